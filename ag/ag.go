@@ -0,0 +1,300 @@
+package ag
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xp"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	Version       = versionPrefix + "v1"
+	versionPrefix = "age-encryption.org/"
+
+	FileKeySize = 16
+
+	DefaultWorkFactor = 18
+	MaxWorkFactor     = 22
+
+	typeX25519 = "X25519"
+	typeScrypt = "scrypt"
+
+	labelX25519 = "age-encryption.org/v1/X25519"
+	labelScrypt = "age-encryption.org/v1/scrypt"
+
+	saltSize = 16
+	bodySize = FileKeySize + chacha20poly1305.Overhead
+)
+
+var (
+	ErrHeader     = errors.New("ag: malformed header")
+	ErrVersion    = errors.New("ag: unsupported version")
+	ErrMAC        = errors.New("ag: header authentication failed")
+	ErrPayload    = errors.New("ag: payload authentication failed")
+	ErrNoMatch    = errors.New("ag: no identity matched")
+	ErrRecipient  = errors.New("ag: no recipients")
+	ErrScrypt     = errors.New("ag: passphrase cannot be combined with recipients")
+	ErrWorkFactor = fmt.Errorf("ag: invalid work factor (1-%d)", MaxWorkFactor)
+)
+
+var workFactorRegexp = regexp.MustCompile(`^[1-9][0-9]*$`)
+
+func IsAge(b []byte) bool { return bytes.HasPrefix(b, []byte(versionPrefix)) }
+
+func Encrypt(r io.Reader, w io.Writer, recipients []kf.PublicKey, passphrase []byte, workFactor int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if passphrase != nil && len(recipients) > 0 {
+		return ErrScrypt
+	}
+	if passphrase == nil && len(recipients) == 0 {
+		return ErrRecipient
+	}
+	fileKey := make([]byte, FileKeySize)
+	if _, err = rand.Read(fileKey); err != nil {
+		return
+	}
+	var stanzas []*stanza
+	if passphrase != nil {
+		s, err := wrapScrypt(passphrase, workFactor, fileKey)
+		if err != nil {
+			return err
+		}
+		stanzas = append(stanzas, s)
+	}
+	for _, recipient := range recipients {
+		s, err := wrapX25519(recipient, fileKey)
+		if err != nil {
+			return err
+		}
+		stanzas = append(stanzas, s)
+	}
+	h, err := marshalHeader(stanzas, fileKey)
+	if err != nil {
+		return
+	}
+	nonce := make([]byte, NonceSize)
+	if _, err = rand.Read(nonce); err != nil {
+		return
+	}
+	if _, err = w.Write(append(h, nonce...)); err != nil {
+		return
+	}
+	key, err := payloadKey(fileKey, nonce)
+	if err != nil {
+		return
+	}
+	return seal(r, w, key)
+}
+
+func Decrypt(r io.Reader, w io.Writer, identities []kf.PrivateKey, passphrase []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	br := bufio.NewReader(r)
+	h, err := readHeader(br)
+	if err != nil {
+		return
+	}
+	fileKey, err := unwrap(h.stanzas, identities, passphrase)
+	if err != nil {
+		return
+	}
+	if err = h.verify(fileKey); err != nil {
+		return
+	}
+	nonce := make([]byte, NonceSize)
+	if _, err = io.ReadFull(br, nonce); err != nil {
+		return ErrHeader
+	}
+	key, err := payloadKey(fileKey, nonce)
+	if err != nil {
+		return
+	}
+	return open(br, w, key)
+}
+
+func unwrap(stanzas []*stanza, identities []kf.PrivateKey, passphrase []byte) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type == typeScrypt && len(stanzas) != 1 {
+			return nil, ErrHeader
+		}
+	}
+	for _, s := range stanzas {
+		switch s.Type {
+		case typeX25519:
+			for _, identity := range identities {
+				key, err := x25519PrivateKey(identity)
+				if err != nil {
+					continue
+				}
+				fileKey, err := unwrapX25519(key, s)
+				if errors.Is(err, ErrNoMatch) {
+					continue
+				}
+				return fileKey, err
+			}
+		case typeScrypt:
+			if passphrase == nil {
+				continue
+			}
+			fileKey, err := unwrapScrypt(passphrase, s)
+			if errors.Is(err, ErrNoMatch) {
+				continue
+			}
+			return fileKey, err
+		}
+	}
+	return nil, ErrNoMatch
+}
+
+func x25519PublicKey(key kf.PublicKey) (*xp.PublicKey, error) {
+	switch k := key.(type) {
+	case *xp.PublicKey:
+		if k.Curve() == xp.X25519 {
+			return k, nil
+		}
+	case *sv.PublicKey:
+		return xp.NewEd25519PublicKey(k)
+	}
+	return nil, kf.ErrKey
+}
+
+func x25519PrivateKey(key kf.PrivateKey) (*xp.PrivateKey, error) {
+	switch k := key.(type) {
+	case *xp.PrivateKey:
+		if k.Curve() == xp.X25519 {
+			return k, nil
+		}
+	case *sv.PrivateKey:
+		return xp.NewEd25519PrivateKey(k)
+	}
+	return nil, kf.ErrKey
+}
+
+func wrapX25519(recipient kf.PublicKey, fileKey []byte) (*stanza, error) {
+	key, err := x25519PublicKey(recipient)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := xp.GenerateKey(xp.X25519)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(key)
+	if err != nil {
+		return nil, err
+	}
+	share := ephemeral.PublicKey().Bytes()
+	body, err := sealFileKey(shared, append(share, key.Bytes()...), labelX25519, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &stanza{Type: typeX25519, Args: []string{b64.EncodeToString(share)}, Body: body}, nil
+}
+
+func unwrapX25519(identity *xp.PrivateKey, s *stanza) ([]byte, error) {
+	if len(s.Args) != 1 || len(s.Body) != bodySize {
+		return nil, ErrHeader
+	}
+	share, err := b64.DecodeString(s.Args[0])
+	if err != nil || len(share) != xp.PublicSizes[xp.X25519] {
+		return nil, ErrHeader
+	}
+	ephemeral, err := xp.NewPublicKey(xp.X25519, share)
+	if err != nil {
+		return nil, ErrHeader
+	}
+	shared, err := identity.ECDH(ephemeral)
+	if err != nil {
+		return nil, ErrHeader
+	}
+	return openFileKey(shared, append(share, identity.PublicKey().Bytes()...), labelX25519, s.Body)
+}
+
+func wrapScrypt(passphrase []byte, workFactor int, fileKey []byte) (*stanza, error) {
+	if workFactor < 1 || workFactor > MaxWorkFactor {
+		return nil, ErrWorkFactor
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key(passphrase, append([]byte(labelScrypt), salt...), 1<<workFactor, 8, 1, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	body, err := sealFileKey(key, nil, "", fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &stanza{Type: typeScrypt, Args: []string{b64.EncodeToString(salt), strconv.Itoa(workFactor)}, Body: body}, nil
+}
+
+func unwrapScrypt(passphrase []byte, s *stanza) ([]byte, error) {
+	if len(s.Args) != 2 || len(s.Body) != bodySize || !workFactorRegexp.MatchString(s.Args[1]) {
+		return nil, ErrHeader
+	}
+	salt, err := b64.DecodeString(s.Args[0])
+	if err != nil || len(salt) != saltSize {
+		return nil, ErrHeader
+	}
+	workFactor, err := strconv.Atoi(s.Args[1])
+	if err != nil || workFactor > MaxWorkFactor {
+		return nil, ErrWorkFactor
+	}
+	key, err := scrypt.Key(passphrase, append([]byte(labelScrypt), salt...), 1<<workFactor, 8, 1, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return openFileKey(key, nil, "", s.Body)
+}
+
+func sealFileKey(secret, salt []byte, label string, fileKey []byte) ([]byte, error) {
+	aead, err := newAEAD(secret, salt, label)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, make([]byte, aead.NonceSize()), fileKey, nil), nil
+}
+
+func openFileKey(secret, salt []byte, label string, body []byte) ([]byte, error) {
+	aead, err := newAEAD(secret, salt, label)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aead.Open(nil, make([]byte, aead.NonceSize()), body, nil)
+	if err != nil {
+		return nil, ErrNoMatch
+	}
+	return fileKey, nil
+}
+
+func newAEAD(secret, salt []byte, label string) (cipher.AEAD, error) {
+	key := secret
+	if label != "" {
+		var err error
+		if key, err = hkdf.Key(sha256.New, secret, salt, label, chacha20poly1305.KeySize); err != nil {
+			return nil, err
+		}
+	}
+	return chacha20poly1305.New(key)
+}
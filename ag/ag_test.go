@@ -0,0 +1,208 @@
+package ag_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	agetest "c2sp.org/CCTV/age"
+	"github.com/jamesliu96/geheim/ag"
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xp"
+)
+
+type vector struct {
+	expect     string
+	payload    []byte
+	identities []kf.PrivateKey
+	passphrase []byte
+	armored    bool
+	hybrid     bool
+	file       []byte
+}
+
+func parseVector(t *testing.T, data []byte) *vector {
+	v := new(vector)
+	var compressed bool
+	for {
+		line, rest, ok := bytes.Cut(data, []byte("\n"))
+		if !ok {
+			t.Fatal("missing payload")
+		}
+		data = rest
+		if len(line) == 0 {
+			break
+		}
+		key, value, _ := strings.Cut(string(line), ": ")
+		switch key {
+		case "expect":
+			v.expect = value
+		case "payload":
+			var err error
+			if v.payload, err = hex.DecodeString(value); err != nil {
+				t.Fatal(err)
+			}
+		case "identity":
+			if strings.HasPrefix(value, "AGE-SECRET-KEY-PQ-") {
+				v.hybrid = true
+				continue
+			}
+			identity, err := ag.DecodeIdentity(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v.identities = append(v.identities, identity)
+		case "passphrase":
+			v.passphrase = []byte(value)
+		case "armored":
+			v.armored = true
+		case "compressed":
+			compressed = true
+		case "file key", "comment":
+		default:
+			t.Fatalf("unknown header %q", key)
+		}
+	}
+	if compressed {
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, err = io.ReadAll(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v.file = data
+	return v
+}
+
+var expectErrors = map[string][]error{
+	"no match":        {ag.ErrNoMatch},
+	"HMAC failure":    {ag.ErrMAC},
+	"header failure":  {ag.ErrHeader, ag.ErrVersion, ag.ErrWorkFactor},
+	"payload failure": {ag.ErrPayload},
+}
+
+func TestVectors(t *testing.T) {
+	entries, err := fs.ReadDir(agetest.Vectors, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "armor") || strings.HasPrefix(name, "hybrid") {
+			continue
+		}
+		data, err := fs.ReadFile(agetest.Vectors, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(name, func(t *testing.T) {
+			v := parseVector(t, data)
+			if v.armored || v.hybrid {
+				t.Skip("unsupported")
+			}
+			var out bytes.Buffer
+			err := ag.Decrypt(bytes.NewReader(v.file), &out, v.identities, v.passphrase)
+			if v.expect == "success" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if sum := sha256.Sum256(out.Bytes()); !bytes.Equal(sum[:], v.payload) {
+					t.Fatalf("payload hash %x, want %x", sum, v.payload)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected %s, got success", v.expect)
+			}
+			expected, ok := expectErrors[v.expect]
+			if !ok {
+				t.Fatalf("unknown expectation %q", v.expect)
+			}
+			for _, e := range expected {
+				if errors.Is(err, e) {
+					return
+				}
+			}
+			t.Fatalf("expected %s, got %v", v.expect, err)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	key, err := xp.GenerateKey(xp.X25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{0, 1, ag.ChunkSize - 1, ag.ChunkSize, ag.ChunkSize + 1, 2 * ag.ChunkSize, 200000} {
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+		for _, mode := range []string{"x25519", "scrypt"} {
+			var (
+				recipients []kf.PublicKey
+				identities []kf.PrivateKey
+				passphrase []byte
+			)
+			if mode == "x25519" {
+				recipients, identities = []kf.PublicKey{key.PublicKey()}, []kf.PrivateKey{key}
+			} else {
+				passphrase = []byte("passphrase")
+			}
+			var ciphertext, out bytes.Buffer
+			if err := ag.Encrypt(bytes.NewReader(plaintext), &ciphertext, recipients, passphrase, 10); err != nil {
+				t.Fatalf("%s %d: %v", mode, size, err)
+			}
+			if err := ag.Decrypt(&ciphertext, &out, identities, passphrase); err != nil {
+				t.Fatalf("%s %d: %v", mode, size, err)
+			}
+			if !bytes.Equal(out.Bytes(), plaintext) {
+				t.Fatalf("%s %d: plaintext mismatch", mode, size)
+			}
+		}
+	}
+}
+
+func TestSSHEd25519(t *testing.T) {
+	key, err := sv.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blob []byte
+	for _, field := range [][]byte{[]byte("ssh-ed25519"), key.PublicKey().Bytes()} {
+		blob = binary.BigEndian.AppendUint32(blob, uint32(len(field)))
+		blob = append(blob, field...)
+	}
+	recipients, err := kf.ParseAuthorizedKeys([]byte("ssh-ed25519 " + base64.StdEncoding.EncodeToString(blob) + " test\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := xp.NewEd25519PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("plaintext")
+	var ciphertext bytes.Buffer
+	if err := ag.Encrypt(bytes.NewReader(plaintext), &ciphertext, recipients, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, identity := range []kf.PrivateKey{key, x} {
+		var out bytes.Buffer
+		if err := ag.Decrypt(bytes.NewReader(ciphertext.Bytes()), &out, []kf.PrivateKey{identity}, nil); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), plaintext) {
+			t.Fatal("plaintext mismatch")
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package ag
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	prefixStanza = "->"
+	prefixMAC    = "---"
+
+	columns    = 64
+	maxLine    = 1 << 12
+	macSize    = sha256.Size
+	infoMAC    = "header"
+	maxStanzas = 1 << 10
+)
+
+var b64 = base64.RawStdEncoding.Strict()
+
+type stanza struct {
+	Type string
+	Args []string
+	Body []byte
+}
+
+type header struct {
+	stanzas []*stanza
+	mac     []byte
+	raw     []byte
+}
+
+func (s *stanza) marshal(b *bytes.Buffer) {
+	b.WriteString(prefixStanza)
+	for _, a := range append([]string{s.Type}, s.Args...) {
+		b.WriteByte(' ')
+		b.WriteString(a)
+	}
+	b.WriteByte('\n')
+	body := b64.EncodeToString(s.Body)
+	for len(body) >= columns {
+		b.WriteString(body[:columns])
+		b.WriteByte('\n')
+		body = body[columns:]
+	}
+	b.WriteString(body)
+	b.WriteByte('\n')
+}
+
+func marshalHeader(stanzas []*stanza, fileKey []byte) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(Version)
+	b.WriteByte('\n')
+	for _, s := range stanzas {
+		s.marshal(&b)
+	}
+	b.WriteString(prefixMAC)
+	mac, err := headerMAC(fileKey, b.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	b.WriteByte(' ')
+	b.WriteString(b64.EncodeToString(mac))
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func headerMAC(fileKey, raw []byte) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nil, infoMAC, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(raw)
+	return h.Sum(nil), nil
+}
+
+func (h *header) verify(fileKey []byte) error {
+	mac, err := headerMAC(fileKey, h.raw)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(mac, h.mac) {
+		return ErrMAC
+	}
+	return nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) || len(line) > maxLine {
+		return "", ErrHeader
+	}
+	if err == io.EOF {
+		return "", ErrHeader
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(line[:len(line)-1]), nil
+}
+
+func validArg(a string) bool {
+	if a == "" {
+		return false
+	}
+	for i := range len(a) {
+		if a[i] < 33 || a[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+func readHeader(r *bufio.Reader) (*header, error) {
+	var raw bytes.Buffer
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if line != Version {
+		if strings.HasPrefix(line, versionPrefix) {
+			return nil, ErrVersion
+		}
+		return nil, ErrHeader
+	}
+	raw.WriteString(line + "\n")
+	h := new(header)
+	for {
+		if line, err = readLine(r); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(line, prefixMAC) {
+			mac, ok := strings.CutPrefix(line, prefixMAC+" ")
+			if !ok {
+				return nil, ErrHeader
+			}
+			if h.mac, err = b64.DecodeString(mac); err != nil || len(h.mac) != macSize {
+				return nil, ErrHeader
+			}
+			raw.WriteString(prefixMAC)
+			h.raw = raw.Bytes()
+			return h, nil
+		}
+		raw.WriteString(line + "\n")
+		args := strings.Split(line, " ")
+		if args[0] != prefixStanza || len(args) < 2 || len(h.stanzas) >= maxStanzas {
+			return nil, ErrHeader
+		}
+		for _, a := range args[1:] {
+			if !validArg(a) {
+				return nil, ErrHeader
+			}
+		}
+		s := &stanza{Type: args[1], Args: args[2:]}
+		for {
+			if line, err = readLine(r); err != nil {
+				return nil, err
+			}
+			raw.WriteString(line + "\n")
+			if len(line) > columns {
+				return nil, ErrHeader
+			}
+			b, err := b64.DecodeString(line)
+			if err != nil {
+				return nil, ErrHeader
+			}
+			s.Body = append(s.Body, b...)
+			if len(line) < columns {
+				break
+			}
+		}
+		h.stanzas = append(h.stanzas, s)
+	}
+}
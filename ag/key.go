@@ -0,0 +1,71 @@
+package ag
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jamesliu96/geheim/internal/bech32"
+	"github.com/jamesliu96/geheim/xp"
+)
+
+const (
+	HRPRecipient = "age"
+	HRPIdentity  = "age-secret-key-"
+)
+
+var ErrKey = errors.New("ag: malformed key")
+
+func EncodeRecipient(key *xp.PublicKey) (string, error) {
+	if key.Curve() != xp.X25519 {
+		return "", ErrKey
+	}
+	return bech32.Encode(bech32.Bech32, HRPRecipient, key.Bytes())
+}
+
+func DecodeRecipient(s string) (*xp.PublicKey, error) {
+	hrp, data, err := bech32.Decode(bech32.Bech32, s)
+	if err != nil || hrp != HRPRecipient || strings.ToLower(s) != s {
+		return nil, ErrKey
+	}
+	return xp.NewPublicKey(xp.X25519, data)
+}
+
+func EncodeIdentity(key *xp.PrivateKey) (string, error) {
+	if key.Curve() != xp.X25519 {
+		return "", ErrKey
+	}
+	s, err := bech32.Encode(bech32.Bech32, HRPIdentity, key.Bytes())
+	return strings.ToUpper(s), err
+}
+
+func DecodeIdentity(s string) (*xp.PrivateKey, error) {
+	hrp, data, err := bech32.Decode(bech32.Bech32, s)
+	if err != nil || hrp != HRPIdentity {
+		return nil, ErrKey
+	}
+	return xp.NewPrivateKey(xp.X25519, data)
+}
+
+func IsRecipient(s string) bool { return strings.HasPrefix(s, HRPRecipient+"1") }
+
+func IsIdentity(data []byte) bool {
+	return strings.Contains(string(data), strings.ToUpper(HRPIdentity)+"1")
+}
+
+func ParseIdentities(data []byte) (identities []*xp.PrivateKey, err error) {
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var identity *xp.PrivateKey
+		if identity, err = DecodeIdentity(line); err != nil {
+			return
+		}
+		identities = append(identities, identity)
+	}
+	if len(identities) == 0 {
+		err = ErrKey
+	}
+	return
+}
@@ -0,0 +1,108 @@
+package ag
+
+import (
+	"bufio"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	ChunkSize = 64 * 1024
+	NonceSize = 16
+
+	infoPayload = "payload"
+)
+
+func payloadKey(fileKey, nonce []byte) ([]byte, error) {
+	return hkdf.Key(sha256.New, fileKey, nonce, infoPayload, chacha20poly1305.KeySize)
+}
+
+func incNonce(nonce []byte) {
+	for i := len(nonce) - 2; i >= 0; i-- {
+		nonce[i]++
+		if nonce[i] != 0 {
+			return
+		}
+	}
+	panic("ag: stream counter overflow")
+}
+
+func seal(r io.Reader, w io.Writer, key []byte) error {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return err
+	}
+	br := bufio.NewReaderSize(r, ChunkSize)
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	buf := make([]byte, ChunkSize, ChunkSize+chacha20poly1305.Overhead)
+	for {
+		n, err := io.ReadFull(br, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		last := n < ChunkSize
+		if !last {
+			if _, err := br.Peek(1); err == io.EOF {
+				last = true
+			} else if err != nil {
+				return err
+			}
+		}
+		if last {
+			nonce[len(nonce)-1] = 1
+		}
+		if _, err := w.Write(aead.Seal(buf[:0], nonce, buf[:n], nil)); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		incNonce(nonce)
+		buf = buf[:ChunkSize]
+	}
+}
+
+func open(r io.Reader, w io.Writer, key []byte) error {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return err
+	}
+	br := bufio.NewReaderSize(r, ChunkSize+chacha20poly1305.Overhead)
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	buf := make([]byte, ChunkSize+chacha20poly1305.Overhead)
+	for first := true; ; first = false {
+		n, err := io.ReadFull(br, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if n < chacha20poly1305.Overhead {
+			return ErrPayload
+		}
+		last := n < len(buf)
+		if last {
+			nonce[len(nonce)-1] = 1
+		}
+		plain, err := aead.Open(nil, nonce, buf[:n], nil)
+		if err != nil && !last {
+			last = true
+			nonce[len(nonce)-1] = 1
+			plain, err = aead.Open(nil, nonce, buf[:n], nil)
+		}
+		if err != nil || last && len(plain) == 0 && !first {
+			return ErrPayload
+		}
+		if _, err := w.Write(plain); err != nil {
+			return err
+		}
+		if last {
+			if _, err := br.Peek(1); err != io.EOF {
+				return ErrPayload
+			}
+			return nil
+		}
+		incNonce(nonce)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/hex"
@@ -10,12 +11,25 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jamesliu96/geheim"
+	"github.com/jamesliu96/geheim/ag"
+	"github.com/jamesliu96/geheim/gf"
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/ks"
+	"github.com/jamesliu96/geheim/oe"
+	"github.com/jamesliu96/geheim/rp"
+	"github.com/jamesliu96/geheim/sf"
+	"github.com/jamesliu96/geheim/xp"
 	"golang.org/x/sys/cpu"
 	"golang.org/x/term"
 )
@@ -35,11 +49,32 @@ func check(err error) {
 	}
 }
 
+const (
+	execCommand = "exec"
+	editCommand = "edit"
+	gitCommand  = "git"
+)
+
+const (
+	gitInit     = "init"
+	gitExport   = "export"
+	gitImport   = "import"
+	gitClean    = gf.CommandClean
+	gitSmudge   = gf.CommandSmudge
+	gitTextconv = "textconv"
+	gitProcess  = "process"
+
+	gitFilterName = "geheim"
+)
+
+var execute, editing bool
+
 var (
 	fDecrypt      = flag.Bool("d", false, "decrypt")
 	fInput        = flag.String("i", os.Stdin.Name(), "input `path`")
 	fOutput       = flag.String("o", os.Stdout.Name(), "output `path`")
 	fKey          = flag.String("p", "", "`key`")
+	fKeyFile      = flag.String("K", "", "key `path`")
 	fAuth         = flag.String("s", "", "authentication `path`")
 	fVerAuthHex   = flag.String("x", "", "verify authentication `hex`")
 	fOverwrite    = flag.Bool("f", false, "overwrite")
@@ -48,6 +83,11 @@ var (
 	fVersion      = flag.Bool("V", false, "version")
 	fPrintAuthHex = flag.Bool("X", false, "print authentication hex")
 	fArchive      = flag.Bool("z", false, "archive")
+	fAge          = flag.Bool("a", false, "age format")
+	fOpenSSL      = flag.String("l", "", "openssl enc `options`")
+	fValues       = flag.Bool("y", false, "values only (json, yaml, dotenv)")
+	fRecipients   []string
+	fIdentities   []string
 
 	fCipher = flag.Int("c", int(geheim.DefaultCipher), fmt.Sprintf("%s (%s)", geheim.CipherDesc, geheim.CipherString))
 	fKDF    = flag.Int("k", int(geheim.DefaultKDF), fmt.Sprintf("%s (%s)", geheim.KDFDesc, geheim.KDFString))
@@ -55,12 +95,41 @@ var (
 	fSec    = flag.Int("e", geheim.DefaultSec, fmt.Sprintf("%s (%s)", geheim.SecDesc, geheim.SecString))
 )
 
+func init() {
+	flag.Func("r", "recipient `key`, path or name", func(s string) error {
+		fRecipients = append(fRecipients, s)
+		return nil
+	})
+	flag.Func("I", "identity `path` or name", func(s string) error {
+		fIdentities = append(fIdentities, s)
+		return nil
+	})
+}
+
 var flags = make(map[string]bool)
 
+func openTTY() (*os.File, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return os.Stdin, nil
+	}
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+	return os.Open(name)
+}
+
 func readKey(question string) (key []byte, err error) {
+	tty, err := openTTY()
+	if err != nil {
+		return
+	}
+	if tty != os.Stdin {
+		defer tty.Close()
+	}
 	for len(key) == 0 {
 		printf("%s", question)
-		key, err = term.ReadPassword(int(os.Stdin.Fd()))
+		key, err = term.ReadPassword(int(tty.Fd()))
 		printf("\n")
 		if err != nil {
 			return
@@ -72,12 +141,14 @@ func readKey(question string) (key []byte, err error) {
 func getKey() (key []byte, err error) {
 	if flags["p"] {
 		key = []byte(*fKey)
+	} else if flags["K"] {
+		key, err = os.ReadFile(*fKeyFile)
 	} else {
 		for {
 			if key, err = readKey("enter key: "); err != nil {
 				return
 			}
-			if !*fDecrypt {
+			if !*fDecrypt || editing {
 				var vkey []byte
 				if vkey, err = readKey("verify key: "); err != nil {
 					return
@@ -93,6 +164,59 @@ func getKey() (key []byte, err error) {
 	return
 }
 
+func getRecipients() (recipients []kf.PublicKey, err error) {
+	store, err := ks.Default()
+	if err != nil {
+		return
+	}
+	for _, arg := range fRecipients {
+		var keys []kf.PublicKey
+		if keys, err = store.ResolveRecipients(arg); err != nil {
+			return
+		}
+		recipients = append(recipients, keys...)
+	}
+	return
+}
+
+func getIdentities() (identities []kf.PrivateKey, err error) {
+	store, err := ks.Default()
+	if err != nil {
+		return
+	}
+	for _, arg := range fIdentities {
+		var data []byte
+		if data, err = store.ResolveIdentity(arg); err != nil {
+			return
+		}
+		if ag.IsIdentity(data) {
+			var keys []*xp.PrivateKey
+			if keys, err = ag.ParseIdentities(data); err != nil {
+				return
+			}
+			for _, key := range keys {
+				identities = append(identities, key)
+			}
+			continue
+		}
+		if kf.IsEncrypted(data) {
+			var passphrase []byte
+			if passphrase, err = readKey(fmt.Sprintf("enter passphrase for %s: ", arg)); err != nil {
+				return
+			}
+			if data, err = kf.DecryptPrivateKey(data, passphrase); err != nil {
+				return
+			}
+		}
+		var identity kf.PrivateKey
+		if identity, err = kf.ParsePrivateKey(data); err != nil {
+			return
+		}
+		identities = append(identities, identity)
+	}
+	return
+}
+
 func getIO() (inputFile, outputFile, authFile *os.File, size int64, err error) {
 	if flags["i"] {
 		if inputFile, err = os.Open(*fInput); err != nil {
@@ -120,7 +244,7 @@ func getIO() (inputFile, outputFile, authFile *os.File, size int64, err error) {
 		if outputFile, err = os.Create(*fOutput); err != nil {
 			return
 		}
-	} else {
+	} else if !execute && !editing {
 		outputFile = os.Stdout
 	}
 	if flags["s"] {
@@ -156,6 +280,392 @@ func getIO() (inputFile, outputFile, authFile *os.File, size int64, err error) {
 	return
 }
 
+func run(args []string, plaintext []byte) error {
+	env, err := sf.Env(plaintext, sf.DetectFormat("", plaintext))
+	clear(plaintext)
+	if err != nil {
+		return fmt.Errorf("ghm: invalid secrets document (wrong key?): %w", err)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), env...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardSignals...)
+	go func() {
+		for s := range signals {
+			cmd.Process.Signal(s)
+		}
+	}()
+	err = cmd.Wait()
+	signal.Stop(signals)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitCode(exitErr))
+	}
+	return err
+}
+
+func tempDir() string {
+	if fi, err := os.Stat("/dev/shm"); err == nil && fi.IsDir() {
+		return "/dev/shm"
+	}
+	return os.TempDir()
+}
+
+var temps sync.Map
+
+func wipe(name string) {
+	if file, err := os.OpenFile(name, os.O_WRONLY, 0); err == nil {
+		if fi, err := file.Stat(); err == nil {
+			io.CopyN(file, zeroReader{}, fi.Size())
+			file.Sync()
+		}
+		file.Close()
+	}
+	os.Remove(name)
+	temps.Delete(name)
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func edit(name string, plaintext []byte, save func([]byte) error) (changed bool, err error) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, append(terminateSignals, os.Interrupt)...)
+	defer signal.Stop(signals)
+	file, err := os.CreateTemp(tempDir(), fmt.Sprintf("%s-*-%s", app, filepath.Base(name)))
+	if err != nil {
+		return
+	}
+	temps.Store(file.Name(), nil)
+	defer wipe(file.Name())
+	var editor atomic.Pointer[os.Process]
+	go func() {
+		for s := range signals {
+			if s == os.Interrupt {
+				continue
+			}
+			if p := editor.Load(); p != nil {
+				p.Signal(s)
+				continue
+			}
+			temps.Range(func(name, _ any) bool {
+				wipe(name.(string))
+				return true
+			})
+			os.Exit(signalCode(s))
+		}
+	}()
+	_, err = file.Write(plaintext)
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return
+	}
+	args := strings.Fields(os.Getenv("EDITOR"))
+	if len(args) == 0 {
+		args = []string{"vi"}
+		if runtime.GOOS == "windows" {
+			args = []string{"notepad"}
+		}
+	}
+	cmd := exec.Command(args[0], append(args[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err = cmd.Start(); err == nil {
+		editor.Store(cmd.Process)
+		err = cmd.Wait()
+		editor.Store(nil)
+	}
+	if err != nil {
+		return false, fmt.Errorf("ghm: editor: %w", err)
+	}
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return
+	}
+	defer clear(data)
+	if bytes.Equal(data, plaintext) {
+		return
+	}
+	return true, save(data)
+}
+
+func replace(name string, write func(io.Writer) error) (err error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return
+	}
+	file, err := os.CreateTemp(filepath.Dir(name), fmt.Sprintf(".%s-*", filepath.Base(name)))
+	if err != nil {
+		return
+	}
+	temps.Store(file.Name(), nil)
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+		temps.Delete(file.Name())
+	}()
+	if err = write(file); err != nil {
+		return
+	}
+	if err = file.Chmod(fi.Mode().Perm()); err != nil {
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	return os.Rename(file.Name(), name)
+}
+
+func gitKeyPath() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--git-common-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("ghm: not a git repository: %w", err)
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), gitFilterName, "key"), nil
+}
+
+func readGitKey() (key []byte, err error) {
+	name, err := gitKeyPath()
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.New("ghm: repository key not found, use git init or git import")
+	}
+	if err != nil {
+		return
+	}
+	return gf.ParseKey(data)
+}
+
+func writeGitKey(key []byte) (err error) {
+	name, err := gitKeyPath()
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return errors.New("ghm: repository key exists")
+	}
+	if err != nil {
+		return
+	}
+	_, err = file.Write(gf.MarshalKey(key))
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	command := fmt.Sprintf("%q %s", filepath.ToSlash(exe), gitCommand)
+	for _, kv := range [][2]string{
+		{"filter." + gitFilterName + ".process", command + " " + gitProcess},
+		{"filter." + gitFilterName + ".clean", command + " " + gitClean},
+		{"filter." + gitFilterName + ".smudge", command + " " + gitSmudge},
+		{"filter." + gitFilterName + ".required", "true"},
+		{"diff." + gitFilterName + ".textconv", command + " " + gitTextconv},
+	} {
+		if err = exec.Command("git", "config", kv[0], kv[1]).Run(); err != nil {
+			return fmt.Errorf("ghm: git config %s: %w", kv[0], err)
+		}
+	}
+	return
+}
+
+func gitCheckout() error {
+	out, err := exec.Command("git", "ls-files", "-z", "--", ":/").Output()
+	if err != nil {
+		return err
+	}
+	var paths bytes.Buffer
+	for _, name := range strings.Split(string(out), "\x00") {
+		file, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		magic := make([]byte, len(gf.Magic))
+		_, err = io.ReadFull(file, magic)
+		file.Close()
+		if err == nil && gf.IsEncrypted(magic) && os.Chtimes(name, time.Time{}, time.Unix(0, 0)) == nil {
+			paths.WriteString(name + "\x00")
+		}
+	}
+	if paths.Len() == 0 {
+		return nil
+	}
+	cmd := exec.Command("git", "--literal-pathspecs", "checkout", "--force", "--pathspec-from-file=-", "--pathspec-file-nul", "--")
+	cmd.Stdin, cmd.Stderr = &paths, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ghm: git checkout: %w", err)
+	}
+	return nil
+}
+
+func wrapGitKey(w io.Writer, data []byte) (err error) {
+	var key []byte
+	kdf := geheim.KDF(*fKDF)
+	if len(fRecipients) > 0 {
+		var recipients []kf.PublicKey
+		if recipients, err = getRecipients(); err != nil {
+			return
+		}
+		if key, err = rp.Wrap(w, recipients); err != nil {
+			return
+		}
+		kdf = geheim.HKDF
+	} else if key, err = getKey(); err != nil {
+		return
+	}
+	_, err = geheim.EncryptArchive(bytes.NewReader(data), w, key, int64(len(data)), geheim.Cipher(*fCipher), geheim.Hash(*fHash), kdf, *fSec, nil)
+	return
+}
+
+func unwrapGitKey(data []byte) (key []byte, err error) {
+	if kf.IsPEM(data) {
+		return gf.ParseKey(data)
+	}
+	r := bytes.NewReader(data)
+	if len(fIdentities) > 0 {
+		var identities []kf.PrivateKey
+		if identities, err = getIdentities(); err != nil {
+			return
+		}
+		if key, err = rp.Unwrap(r, identities); err != nil {
+			return
+		}
+	} else if key, err = getKey(); err != nil {
+		return
+	}
+	var b bytes.Buffer
+	if _, _, err = geheim.DecryptArchive(r, &b, key, nil); err != nil {
+		return
+	}
+	defer clear(b.Bytes())
+	return gf.ParseKey(b.Bytes())
+}
+
+func gitFilter(key []byte, command string, data []byte) ([]byte, error) {
+	switch command {
+	case gitClean:
+		if plaintext, err := gf.Decrypt(key, data); err == nil {
+			clear(plaintext)
+			return data, nil
+		}
+		return gf.Encrypt(key, data)
+	case gitSmudge, gitTextconv:
+		if !gf.IsEncrypted(data) {
+			return data, nil
+		}
+		return gf.Decrypt(key, data)
+	}
+	return nil, fmt.Errorf("ghm: invalid filter command %q", command)
+}
+
+func git(args []string) (err error) {
+	if len(args) == 0 {
+		flag.Usage()
+		return
+	}
+	if args[0] == gitExport || args[0] == gitImport {
+		flag.CommandLine.Parse(args[1:])
+		flag.Visit(func(f *flag.Flag) { flags[f.Name] = true })
+		if flag.NArg() > 0 {
+			flag.Usage()
+			return
+		}
+		*fDecrypt = args[0] == gitImport
+	}
+	switch args[0] {
+	case gitInit:
+		var key []byte
+		if key, err = gf.GenerateKey(); err != nil {
+			return
+		}
+		return writeGitKey(key)
+	case gitImport:
+		var data, key []byte
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return
+		}
+		if key, err = unwrapGitKey(data); err != nil {
+			return
+		}
+		if existing, e := readGitKey(); e == nil && bytes.Equal(existing, key) {
+			return gitCheckout()
+		}
+		if err = writeGitKey(key); err != nil {
+			return
+		}
+		return gitCheckout()
+	}
+	key, err := readGitKey()
+	if err != nil {
+		return
+	}
+	var data []byte
+	switch args[0] {
+	case gitExport:
+		if term.IsTerminal(int(os.Stdout.Fd())) {
+			return errors.New("ghm: invalid terminal i/o")
+		}
+		if len(fRecipients) > 0 || flags["p"] || flags["K"] {
+			err = wrapGitKey(os.Stdout, gf.MarshalKey(key))
+		} else {
+			_, err = os.Stdout.Write(gf.MarshalKey(key))
+		}
+	case gitClean, gitSmudge:
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return
+		}
+		if data, err = gitFilter(key, args[0], data); err != nil {
+			return
+		}
+		_, err = os.Stdout.Write(data)
+	case gitTextconv:
+		if len(args) < 2 {
+			flag.Usage()
+			return
+		}
+		if data, err = os.ReadFile(args[1]); err != nil {
+			return
+		}
+		if data, err = gitFilter(key, args[0], data); err != nil {
+			return
+		}
+		_, err = os.Stdout.Write(data)
+	case gitProcess:
+		err = gf.Serve(os.Stdin, os.Stdout, func(command, pathname string, data []byte) ([]byte, error) {
+			data, err := gitFilter(key, command, data)
+			if err != nil {
+				printf("error: %s: %v\n", pathname, err)
+			}
+			return data, err
+		})
+	default:
+		flag.Usage()
+	}
+	return
+}
+
 func cpuFeatures() (d []string) {
 	var arch any
 	switch runtime.GOARCH {
@@ -203,16 +713,47 @@ func main() {
 	}()
 	flag.Usage = func() {
 		printf(`usage: %s [option]...
+       %s %s [option]... -i <path> [--] <command> [arg]...
+       %s %s [option]... -i <path>
+       %s %s %s|%s|%s|%s
+       %s %s %s [option]... > key
+       %s %s %s [option]... < key
+       %s %s %s <path>
 options:
-`, app)
+`, app, app, execCommand, app, editCommand, app, gitCommand, gitInit, gitClean, gitSmudge, gitProcess, app, gitCommand, gitExport, app, gitCommand, gitImport, app, gitCommand, gitTextconv)
 		flag.PrintDefaults()
 	}
 	if len(os.Args) < 2 {
 		flag.Usage()
 		return
 	}
-	flag.Parse()
+	args := os.Args[1:]
+	if args[0] == gitCommand {
+		check(git(args[1:]))
+		return
+	}
+	switch args[0] {
+	case execCommand:
+		execute, args = true, args[1:]
+	case editCommand:
+		editing, args = true, args[1:]
+	}
+	flag.CommandLine.Parse(args)
 	flag.Visit(func(f *flag.Flag) { flags[f.Name] = true })
+	if execute {
+		if flag.NArg() < 1 || !flags["i"] || flags["o"] || len(fRecipients) > 0 {
+			flag.Usage()
+			return
+		}
+		*fDecrypt = true
+	}
+	if editing {
+		if flag.NArg() > 0 || !flags["i"] || flags["o"] || len(fRecipients) > 0 {
+			flag.Usage()
+			return
+		}
+		*fDecrypt = true
+	}
 	if *fVersion {
 		if *fVerbose {
 			printf("%s [%s-%s] [%s] {%d} %s (%s) %s\n", app, runtime.GOOS, runtime.GOARCH, runtime.Version(), runtime.NumCPU(), gitTag, gitRev, cpuFeatures())
@@ -232,13 +773,102 @@ options:
 	check(err)
 	if *fVerbose {
 		printf("%-8s%s\n", "INPUT", inputFile.Name())
-		printf("%-8s%s\n", "OUTPUT", outputFile.Name())
+		if outputFile != nil {
+			printf("%-8s%s\n", "OUTPUT", outputFile.Name())
+		}
 		if authFile != nil {
 			printf("%-8s%s\n", "AUTH", authFile.Name())
 		}
 	}
-	key, err := getKey()
-	check(err)
+	if *fDecrypt && len(fRecipients) > 0 {
+		check(errors.New("ghm: recipients are only used for encryption"))
+	}
+	if !*fDecrypt && len(fIdentities) > 0 {
+		check(errors.New("ghm: identities are only used for decryption"))
+	}
+	var (
+		key        []byte
+		kdf        = geheim.KDF(*fKDF)
+		recipients []kf.PublicKey
+		identities []kf.PrivateKey
+		stanzas    bytes.Buffer
+	)
+	input, output := io.Reader(inputFile), io.Writer(outputFile)
+	var plaintext bytes.Buffer
+	if execute || editing {
+		output = &plaintext
+	}
+	age, openssl := *fAge, flags["l"]
+	if *fDecrypt {
+		br := bufio.NewReader(input)
+		b, _ := br.Peek(len(ag.Version))
+		age, openssl = ag.IsAge(b), oe.IsSalted(b)
+		input = br
+	}
+	if age {
+		if *fArchive || authFile != nil || flags["x"] {
+			check(errors.New("ghm: age format does not support archive or authentication"))
+		}
+		if *fVerbose {
+			printf("%-8s%s\n", "FORMAT", "AGE")
+		}
+	}
+	values := *fValues
+	if values {
+		if age || openssl || *fArchive || authFile != nil || flags["x"] {
+			check(errors.New("ghm: values mode does not support other formats, archive or authentication"))
+		}
+		if *fVerbose {
+			printf("%-8s%s\n", "FORMAT", "VALUES")
+		}
+	}
+	params := oe.DefaultParams
+	if openssl {
+		if *fArchive || authFile != nil || flags["x"] || len(fRecipients) > 0 || len(fIdentities) > 0 {
+			check(errors.New("ghm: openssl format does not support archive, authentication or recipients"))
+		}
+		if *fDecrypt && !flags["l"] {
+			check(errors.New("ghm: openssl format does not record its parameters, use -l to specify them"))
+		}
+		if flags["l"] {
+			params, err = oe.ParseParams(*fOpenSSL)
+			check(err)
+		}
+		if *fVerbose {
+			printf("%-8s%s\n", "FORMAT", "OPENSSL")
+			printf("%-8s%s\n", "PARAMS", params)
+		}
+	}
+	if editing && (age || values || openssl) {
+		check(errors.New("ghm: edit does not support age, openssl or values formats"))
+	}
+	if (execute || editing) && !age && !values && !openssl && !*fArchive && authFile == nil && !flags["x"] {
+		check(errors.New("ghm: exec and edit require authentication, use -z, -s or -x"))
+	}
+	switch {
+	case len(fRecipients) > 0:
+		recipients, err = getRecipients()
+		check(err)
+		if !age && !values {
+			key, err = rp.Wrap(output, recipients)
+			check(err)
+			kdf = geheim.HKDF
+		}
+	case len(fIdentities) > 0:
+		identities, err = getIdentities()
+		check(err)
+		if !age && !values {
+			r := input
+			if editing {
+				r = io.TeeReader(input, &stanzas)
+			}
+			key, err = rp.Unwrap(r, identities)
+			check(err)
+		}
+	default:
+		key, err = getKey()
+		check(err)
+	}
 	var authex []byte
 	if *fDecrypt && !*fArchive {
 		if authFile != nil {
@@ -250,7 +880,6 @@ options:
 			check(err)
 		}
 	}
-	input, output := io.Reader(inputFile), io.Writer(outputFile)
 	var pw *geheim.ProgressWriter
 	if *fProgress {
 		pw = geheim.NewProgressWriter(size)
@@ -261,18 +890,57 @@ options:
 	if *fVerbose {
 		printFunc = geheim.NewDefaultPrintFunc(os.Stderr)
 	}
+	var header geheim.Header
+	if editing {
+		pf := printFunc
+		printFunc = func(version int, h geheim.Header, key []byte) error {
+			header = h
+			if pf != nil {
+				return pf(version, h, key)
+			}
+			return nil
+		}
+	}
 	var auth []byte
-	if *fArchive {
+	if values {
+		var data []byte
+		if data, err = io.ReadAll(input); err == nil {
+			format := sf.DetectFormat(*fInput, data)
+			if *fVerbose {
+				printf("%-8s%s\n", "VALUES", sf.FormatNames[format])
+			}
+			if *fDecrypt {
+				data, err = sf.Decrypt(data, format, key, identities)
+			} else {
+				data, err = sf.Encrypt(data, format, key, recipients)
+			}
+			if err == nil {
+				_, err = output.Write(data)
+			}
+		}
+	} else if openssl {
+		if *fDecrypt {
+			err = oe.Decrypt(input, output, key, params)
+		} else {
+			err = oe.Encrypt(input, output, key, params)
+		}
+	} else if age {
+		if *fDecrypt {
+			err = ag.Decrypt(input, output, identities, key)
+		} else {
+			err = ag.Encrypt(input, output, recipients, key, ag.DefaultWorkFactor)
+		}
+	} else if *fArchive {
 		if *fDecrypt {
 			auth, authex, err = geheim.DecryptArchive(input, output, key, printFunc)
 		} else {
-			auth, err = geheim.EncryptArchive(input, output, key, size, geheim.Cipher(*fCipher), geheim.Hash(*fHash), geheim.KDF(*fKDF), *fSec, printFunc)
+			auth, err = geheim.EncryptArchive(input, output, key, size, geheim.Cipher(*fCipher), geheim.Hash(*fHash), kdf, *fSec, printFunc)
 		}
 	} else {
 		if *fDecrypt {
 			auth, err = geheim.DecryptVerify(input, output, key, authex, printFunc)
 		} else {
-			auth, err = geheim.Encrypt(input, output, key, geheim.Cipher(*fCipher), geheim.Hash(*fHash), geheim.KDF(*fKDF), *fSec, printFunc)
+			auth, err = geheim.Encrypt(input, output, key, geheim.Cipher(*fCipher), geheim.Hash(*fHash), kdf, *fSec, printFunc)
 		}
 	}
 	if pw != nil {
@@ -295,4 +963,44 @@ options:
 			check(err)
 		}
 	}
+	if execute {
+		check(run(flag.Args(), plaintext.Bytes()))
+	}
+	if editing {
+		cipher, hash, kdf, sec, _, _ := header.Get()
+		changed, err := edit(*fInput, plaintext.Bytes(), func(data []byte) error {
+			if err := inputFile.Close(); err != nil {
+				return err
+			}
+			return replace(*fInput, func(w io.Writer) (err error) {
+				if _, err = w.Write(stanzas.Bytes()); err != nil {
+					return
+				}
+				if *fArchive {
+					auth, err = geheim.EncryptArchive(bytes.NewReader(data), w, key, int64(len(data)), cipher, hash, kdf, sec, nil)
+				} else {
+					auth, err = geheim.Encrypt(bytes.NewReader(data), w, key, cipher, hash, kdf, sec, nil)
+				}
+				return
+			})
+		})
+		clear(plaintext.Bytes())
+		check(err)
+		if !changed {
+			if *fVerbose {
+				printf("%-8s%s\n", "EDIT", "UNCHANGED")
+			}
+			return
+		}
+		if *fVerbose {
+			printf("%-8s%s\n", "EDIT", "CHANGED")
+		}
+		if *fVerbose || *fPrintAuthHex || flags["x"] {
+			printf("%-8s%x\n", "AUTHED", auth)
+		}
+		if authFile != nil {
+			check(authFile.Close())
+			check(os.WriteFile(*fAuth, auth, 0666))
+		}
+	}
 }
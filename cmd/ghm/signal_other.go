@@ -0,0 +1,22 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+var (
+	terminateSignals []os.Signal
+	forwardSignals   = []os.Signal{os.Interrupt}
+)
+
+func signalCode(os.Signal) int { return 1 }
+
+func exitCode(err *exec.ExitError) int {
+	if code := err.ExitCode(); code >= 0 {
+		return code
+	}
+	return 1
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+var (
+	terminateSignals = []os.Signal{syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+	forwardSignals   = append([]os.Signal{os.Interrupt, syscall.SIGWINCH}, terminateSignals...)
+)
+
+func signalCode(s os.Signal) int {
+	if s, ok := s.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
+func exitCode(err *exec.ExitError) int {
+	if status, ok := err.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return signalCode(status.Signal())
+	}
+	return err.ExitCode()
+}
@@ -1,15 +1,36 @@
 package main
 
 import (
-	"crypto/mlkem"
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/jamesliu96/geheim"
+	"github.com/jamesliu96/geheim/ag"
+	"github.com/jamesliu96/geheim/ds"
+	"github.com/jamesliu96/geheim/jw"
+	"github.com/jamesliu96/geheim/kc"
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/ks"
+	"github.com/jamesliu96/geheim/mf"
+	"github.com/jamesliu96/geheim/mn"
+	"github.com/jamesliu96/geheim/ss"
 	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xc"
 	"github.com/jamesliu96/geheim/xp"
+	"golang.org/x/crypto/ssh"
 	"golang.org/x/term"
 )
 
@@ -35,11 +56,64 @@ const (
 	d = "d"
 	p = "p"
 	x = "x"
+	k = "k"
 	g = "g"
 	s = "s"
 	v = "v"
+	c = "c"
+	u = "u"
+	f = "f"
+	l = "l"
+	i = "i"
+	o = "o"
+	r = "r"
+	t = "t"
+	j = "j"
+	m = "m"
+	n = "n"
+	h = "h"
+	y = "y"
+	w = "w"
+	a = "a"
+	b = "b"
+
+	p256 = "p256"
+	p384 = "p384"
+	p521 = "p521"
+	x256 = "x256"
+	x384 = "x384"
+	x521 = "x521"
+
+	C = "C"
+	R = "R"
+	T = "T"
+
+	S = "S"
+	V = "V"
+
+	K = "K"
+	J = "J"
+	W = "W"
+	E = "E"
+	D = "D"
+
+	X = "X"
+	Q = "Q"
 )
 
+var algorithms = map[string]kf.Algorithm{
+	q:    kf.MLKEM768,
+	p:    kf.X25519,
+	x:    kf.X25519,
+	g:    kf.Ed25519,
+	p256: kf.P256,
+	p384: kf.P384,
+	p521: kf.P521,
+	x256: kf.P256,
+	x384: kf.P384,
+	x521: kf.P521,
+}
+
 func usage() {
 	printf(`%s %s (%s)
 usage: %s %s > private.key                               # mlkem pair
@@ -54,6 +128,11 @@ usage: %s %s > private.key                               # mlkem pair
        %s %s > private.key                               # ecdh pair
        %s %s <private_hex> [public_hex] > shared.key     # ecdh exchange
        %s %s [public_hex] < private.key > shared.key     # ecdh exchange
+       %s %s|%s|%s > private.key                  # nist ecdh pair
+       %s %s|%s|%s <private_hex> [public_hex]     # nist ecdh exchange
+       %s %s|%s|%s [public_hex] < private.key     # nist ecdh exchange
+       %s %s <public_hex> <public_hex> < shared.key      # key derivation
+       %s %s <shared_hex> <public_hex> <public_hex>      # key derivation
        %s %s > private.key                               # ecdsa pair
        %s %s <message> <private_hex> > signature.bin     # ecdsa sign
        %s %s <message> < private.key > signature.bin     # ecdsa sign
@@ -61,7 +140,67 @@ usage: %s %s > private.key                               # mlkem pair
        %s %s <message> <public_hex> <signature_hex>      # ecdsa verify
        %s %s <message> <public_hex> < signature.bin      # ecdsa verify
        %s %s <public_hex> < signature.bin < message.bin  # ecdsa verify
-`, app, gitTag, gitRev, app, q, app, z, app, z, app, e, app, e, app, d, app, d, app, d, app, d, app, p, app, x, app, x, app, g, app, s, app, s, app, s, app, v, app, v, app, v)
+       %s %s < private.key > protected.key               # passphrase protect
+       %s %s < protected.key > private.key               # passphrase unprotect
+       %s %s <encoded_public>                            # fingerprint
+       %s %s < public.key                                # fingerprint
+       %s %s                                             # keystore list
+       %s %s <name> < private.key                        # keystore import identity
+       %s %s <name> < public.key                         # keystore import contact
+       %s %s <name> <encoded_public>                     # keystore import contact
+       %s %s <name> > public.key                         # keystore export
+       %s %s -p <name> > protected.key                   # keystore export identity
+       %s %s <name>                                      # keystore delete
+       %s %s [option]... <secret> > shares.txt           # secret split
+       %s %s [option]... < secret.key > shares.txt       # secret split
+       %s %s < shares.txt > secret.key                   # secret combine
+       %s %s < private.key > words.txt                   # mnemonic export
+       %s %s [option]... <word>... > private.key         # mnemonic restore
+       %s %s [option]... < words.txt > private.key       # mnemonic restore
+       %s %s [option]... <path> <seed_hex> > private.key # hd derivation
+       %s %s [option]... <path> < seed.bin > private.key # hd derivation
+       %s %s -w [option]... <path> < words.txt           # hd derivation
+       %s %s [option]... <key_hex> > x25519.key          # ed25519 to x25519
+       %s %s [option]... < ed25519.key > x25519.key      # ed25519 to x25519
+       %s %s <public_hex> > minisign.pub                 # minisign public key
+       %s %s < public.key > minisign.pub                 # minisign public key
+       %s %s [option]... <dir> <private_hex> > manifest  # manifest sign
+       %s %s [option]... <dir> < private.key > manifest  # manifest sign
+       %s %s <dir> <public_hex> < manifest               # manifest verify
+       %s %s [option]... <public_hex> <private_hex>      # key certify
+       %s %s [option]... <public_hex> < private.key      # key certify
+       %s %s [option]... <public_hex> <private_hex>      # key revoke
+       %s %s [option]... [public_hex] < private.key      # key revoke
+       %s %s [option]... <public_hex>                    # key trust
+       %s %s [option]... <public_hex> <message> <sig>    # key trust verify
+       %s %s [option]... <public_hex> < signature.bin    # key trust verify
+       %s %s [option]... <message> <private_hex>         # ssh sign
+       %s %s [option]... < private.key < message.bin     # ssh sign
+       %s %s -s <sig> [option]... <message> [public_hex] # ssh verify
+       %s %s -s <sig> [public_hex] < message.bin         # ssh verify
+       %s %s -g -f <allowed_signers> < commit.txt        # git verify
+       %s %s [option]... <public_hex> > public.jwk       # jwk export
+       %s %s [option]... < private.key > private.jwk     # jwk export
+       %s %s [option]... < key.jwk > key                 # jwk import
+       %s %s [option]... <message> <private_hex>         # jws sign
+       %s %s [option]... < private.key < message.bin     # jws sign
+       %s %s <public_hex> [token]                        # jws verify
+       %s %s <public_hex> [message] > token.txt          # jwe encrypt
+       %s %s <private_hex> [token] > message.bin         # jwe decrypt
+       %s %s <token> < private.key > message.bin         # jwe decrypt
+       %s %s [option]... [private_hex] > certificate.pem # x509 self-sign
+       %s %s -r <request> -c <ca> [private_hex]          # x509 sign request
+       %s %s [option]... [private_hex] > request.pem     # x509 request
+`, app, gitTag, gitRev, app, q, app, z, app, z, app, e, app, e, app, d, app, d, app, d, app, d, app, p, app, x, app, x, app, p256, p384, p521, app, x256, x384, x521, app, x256, x384, x521, app, k, app, k, app, g, app, s, app, s, app, s, app, v, app, v, app, v, app, c, app, u, app, f, app, f, app, l, app, i, app, i, app, i, app, o, app, o, app, r, app, t, app, t, app, j, app, m, app, n, app, n, app, h, app, h, app, h, app, y, app, y, app, w, app, w, app, a, app, a, app, b, app, C, app, C, app, R, app, R, app, T, app, T, app, T, app, S, app, S, app, V, app, V, app, V, app, K, app, K, app, K, app, J, app, J, app, W, app, E, app, D, app, D, app, X, app, X, app, Q)
+}
+
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		printf("usage: %s %s [option]... %s\noptions:\n", app, name, args)
+		fs.PrintDefaults()
+	}
+	return fs
 }
 
 func isTerminal(file *os.File) bool {
@@ -76,6 +215,374 @@ var (
 	stdinTerm  = isTerminal(os.Stdin)
 )
 
+var stdin = bufio.NewReader(os.Stdin)
+
+func protectFlags(fs *flag.FlagSet) (protect *bool, kdf, sec *int) {
+	protect = fs.Bool("p", false, "protect with passphrase")
+	kdf = fs.Int("k", int(geheim.DefaultKDF), fmt.Sprintf("%s (%s)", geheim.KDFDesc, geheim.KDFString))
+	sec = fs.Int("e", geheim.DefaultSec, fmt.Sprintf("%s (%s)", geheim.SecDesc, geheim.SecString))
+	return
+}
+
+func modeFlags(fs *flag.FlagSet) (mode *int, context *string) {
+	mode = fs.Int("m", int(sv.Pure), fmt.Sprintf("mode (%s)", sv.ModeString))
+	context = fs.String("c", "", "`context`")
+	return
+}
+
+func checkDetached(mode sv.Mode, context string) error {
+	if mode != sv.Pure || context != "" {
+		return errors.New("xp: signature file requires pure mode")
+	}
+	return nil
+}
+
+func detachedPublicKey(s string) (*ds.PublicKey, error) {
+	if data, err := os.ReadFile(s); err == nil {
+		if key, err := ds.ParsePublicKey(data); err == nil {
+			return key, nil
+		}
+	}
+	if key, err := ds.ParsePublicKey([]byte(s)); err == nil {
+		return key, nil
+	}
+	key, err := hexPublicKey(kf.Ed25519, s)
+	if err != nil {
+		return nil, err
+	}
+	return ds.NewPublicKey(key.(*sv.PublicKey))
+}
+
+func readJWK() (*jw.JWK, error) {
+	var raw json.RawMessage
+	dec := json.NewDecoder(stdin)
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	rest := bufio.NewReader(io.MultiReader(dec.Buffered(), stdin))
+	if b, _ := rest.Peek(1); len(b) > 0 && b[0] == '\n' {
+		rest.ReadByte()
+	}
+	stdin = rest
+	return jw.ParseJWK(raw)
+}
+
+func jwkPrivateKey(algorithm kf.Algorithm, s string) (kf.PrivateKey, error) {
+	if data, err := os.ReadFile(s); err == nil && jw.IsJSON(data) {
+		jwk, err := jw.ParseJWK(data)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwk.PrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		return key, checkAlgorithm(algorithm, key)
+	}
+	return hexPrivateKey(algorithm, s)
+}
+
+func jwkPublicKey(algorithm kf.Algorithm, s string) (kf.PublicKey, error) {
+	if data, err := os.ReadFile(s); err == nil && jw.IsJSON(data) {
+		jwk, err := jw.ParseJWK(data)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		return key, checkAlgorithm(algorithm, key)
+	}
+	return hexPublicKey(algorithm, s)
+}
+
+func readJWKPrivateKey(algorithm kf.Algorithm) (kf.PrivateKey, error) {
+	if b, _ := stdin.Peek(1); jw.IsJSON(b) {
+		jwk, err := readJWK()
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwk.PrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		return key, checkAlgorithm(algorithm, key)
+	}
+	return readPrivateKey(algorithm)
+}
+
+func splitGitSignature(object []byte) (payload, signature []byte, err error) {
+	header, body, _ := bytes.Cut(object, []byte("\n\n"))
+	lines := bytes.Split(header, []byte("\n"))
+	for i, line := range lines {
+		value, ok := bytes.CutPrefix(line, []byte("gpgsig "))
+		if !ok {
+			continue
+		}
+		signature = append(bytes.Clone(value), '\n')
+		j := i + 1
+		for ; j < len(lines) && bytes.HasPrefix(lines[j], []byte(" ")); j++ {
+			signature = append(append(signature, lines[j][1:]...), '\n')
+		}
+		payload = bytes.Join(slices.Concat(lines[:i], lines[j:]), []byte("\n"))
+		payload = append(append(payload, "\n\n"...), body...)
+		return
+	}
+	if i := bytes.Index(object, []byte("\n-----BEGIN "+sv.TypeSSHSignature+"-----")); i >= 0 {
+		return object[:i+1], object[i+1:], nil
+	}
+	return nil, nil, errors.New("xp: unsigned git object")
+}
+
+func openTTY() (*os.File, error) {
+	if stdinTerm {
+		return os.Stdin, nil
+	}
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+	return os.Open(name)
+}
+
+func readPassphrase(question string) (passphrase []byte, err error) {
+	tty, err := openTTY()
+	if err != nil {
+		return
+	}
+	if tty != os.Stdin {
+		defer tty.Close()
+	}
+	for len(passphrase) == 0 {
+		printf("%s", question)
+		passphrase, err = term.ReadPassword(int(tty.Fd()))
+		printf("\n")
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func readNewPassphrase() (passphrase []byte, err error) {
+	for {
+		if passphrase, err = readPassphrase("enter new passphrase: "); err != nil {
+			return
+		}
+		var vpassphrase []byte
+		if vpassphrase, err = readPassphrase("verify new passphrase: "); err != nil {
+			return
+		}
+		if bytes.Equal(passphrase, vpassphrase) {
+			return
+		}
+	}
+}
+
+func checkAlgorithm(algorithm kf.Algorithm, key any) error {
+	a, err := kf.AlgorithmOf(key)
+	if err != nil {
+		return err
+	}
+	if a != algorithm {
+		return fmt.Errorf("xp: unexpected %s key (want %s)", kf.AlgorithmNames[a], kf.AlgorithmNames[algorithm])
+	}
+	return nil
+}
+
+func convertPrivateKey(algorithm kf.Algorithm, key kf.PrivateKey) (kf.PrivateKey, error) {
+	if k, ok := key.(*sv.PrivateKey); ok && algorithm == kf.X25519 {
+		return xp.NewEd25519PrivateKey(k)
+	}
+	return key, checkAlgorithm(algorithm, key)
+}
+
+func convertPublicKey(algorithm kf.Algorithm, key kf.PublicKey) (kf.PublicKey, error) {
+	if k, ok := key.(*sv.PublicKey); ok && algorithm == kf.X25519 {
+		return xp.NewEd25519PublicKey(k)
+	}
+	return key, checkAlgorithm(algorithm, key)
+}
+
+func parsePrivateKey(algorithm kf.Algorithm, data []byte) (key kf.PrivateKey, err error) {
+	if kf.IsEncrypted(data) {
+		var passphrase []byte
+		if passphrase, err = readPassphrase("enter passphrase: "); err != nil {
+			return
+		}
+		if data, err = kf.DecryptPrivateKey(data, passphrase); err != nil {
+			return
+		}
+	}
+	if kf.IsPEM(data) || algorithm == 0 {
+		key, err = kf.ParsePrivateKey(data)
+	} else {
+		key, err = kf.NewPrivateKey(algorithm, data)
+	}
+	if err != nil || algorithm == 0 {
+		return
+	}
+	return convertPrivateKey(algorithm, key)
+}
+
+func parsePublicKey(algorithm kf.Algorithm, data []byte) (key kf.PublicKey, err error) {
+	if kf.IsPEM(data) {
+		key, err = kf.ParsePublicKey(data)
+	} else {
+		key, err = kf.NewPublicKey(algorithm, data)
+	}
+	if err != nil {
+		return
+	}
+	return convertPublicKey(algorithm, key)
+}
+
+func readPrivateKey(algorithm kf.Algorithm) (kf.PrivateKey, error) {
+	data, err := kf.ReadKey(stdin, kf.PrivateSizes[algorithm])
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKey(algorithm, data)
+}
+
+func readPublicKey(algorithm kf.Algorithm) (kf.PublicKey, error) {
+	data, err := kf.ReadKey(stdin, kf.PublicSizes[algorithm])
+	if err != nil {
+		return nil, err
+	}
+	return parsePublicKey(algorithm, data)
+}
+
+func hexPrivateKey(algorithm kf.Algorithm, s string) (kf.PrivateKey, error) {
+	store, err := ks.Default()
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.Identity(s)
+	if errors.Is(err, ks.ErrName) || errors.Is(err, ks.ErrNotExist) {
+		if data, err = hex.DecodeString(s); err != nil {
+			data, err = store.ResolveIdentity(s)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKey(algorithm, data)
+}
+
+func resolvePublicKey(s string) (key kf.PublicKey, data []byte, err error) {
+	store, err := ks.Default()
+	if err != nil {
+		return
+	}
+	key, err = store.Public(s)
+	if errors.Is(err, ks.ErrName) || errors.Is(err, ks.ErrNotExist) {
+		if data, err = hex.DecodeString(s); err != nil {
+			key, err = store.ResolveRecipient(s)
+		}
+	}
+	return
+}
+
+func hexPublicKey(algorithm kf.Algorithm, s string) (kf.PublicKey, error) {
+	key, data, err := resolvePublicKey(s)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		return convertPublicKey(algorithm, key)
+	}
+	return parsePublicKey(algorithm, data)
+}
+
+func hexPublic(s string) ([]byte, error) {
+	key, data, err := resolvePublicKey(s)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		return key.Bytes(), nil
+	}
+	return data, nil
+}
+
+func printKey(w io.Writer, private kf.PrivateKey, public kf.PublicKey) error {
+	enc, err := kf.EncodePublicKey(public)
+	if err != nil {
+		return err
+	}
+	fp, err := kf.Fingerprint(public)
+	if err != nil {
+		return err
+	}
+	if private != nil {
+		fmt.Fprintf(w, "%-5s%x\n", "priv", private.Bytes())
+	}
+	fmt.Fprintf(w, "%-5s%x\n%-5s%s\n%-5s%x\n", "pub", public.Bytes(), "enc", enc, "fp", fp)
+	if key, ok := public.(*xp.PublicKey); ok && key.Curve() == xp.X25519 {
+		recipient, err := ag.EncodeRecipient(key)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%-5s%s\n", "age", recipient)
+	}
+	return nil
+}
+
+func writePrivateKey(key kf.PrivateKey, passphrase []byte, kdf geheim.KDF, sec int) error {
+	public, err := kf.Public(key)
+	if err != nil {
+		return err
+	}
+	if stdoutTerm && passphrase == nil {
+		return printKey(os.Stdout, key, public)
+	}
+	var data []byte
+	if passphrase != nil {
+		data, err = kf.MarshalEncryptedPrivateKey(key, passphrase, kdf, sec)
+	} else {
+		data, err = kf.MarshalPrivateKey(key)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stdout.Write(data); err != nil {
+		return err
+	}
+	if passphrase != nil {
+		key = nil
+	}
+	return printKey(os.Stderr, key, public)
+}
+
+func writePublicKey(key kf.PrivateKey) error {
+	public, err := kf.Public(key)
+	if err != nil {
+		return err
+	}
+	if stdoutTerm {
+		return printKey(os.Stdout, key, public)
+	}
+	data, err := kf.MarshalPublicKey(public)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stdout.Write(data); err != nil {
+		return err
+	}
+	return printKey(os.Stderr, key, public)
+}
+
+func writeBytes(b []byte) {
+	if stdoutTerm {
+		fmt.Printf("%x\n", b)
+	} else {
+		os.Stdout.Write(b)
+		printf("%x\n", b)
+	}
+}
+
 func main() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -89,63 +596,50 @@ func main() {
 		return
 	}
 	switch os.Args[1] {
-	case q:
-		dk, err := mlkem.GenerateKey768()
-		dkBytes := dk.Bytes()
+	case q, p, p256, p384, p521, g:
+		fs := newFlagSet(os.Args[1], "> private.key")
+		fProtect, fKDF, fSec := protectFlags(fs)
+		check(fs.Parse(os.Args[2:]))
+		key, err := kf.GenerateKey(algorithms[os.Args[1]])
 		check(err)
-		ekBytes := dk.EncapsulationKey().Bytes()
-		if stdoutTerm {
-			fmt.Printf("%-5s%x\n%-5s%x\n", "priv", dkBytes, "pub", ekBytes)
-		} else {
-			os.Stdout.Write(dkBytes)
-			printf("%-5s%x\n%-5s%x\n", "priv", dkBytes, "pub", ekBytes)
+		var passphrase []byte
+		if *fProtect {
+			passphrase, err = readNewPassphrase()
+			check(err)
 		}
+		check(writePrivateKey(key, passphrase, geheim.KDF(*fKDF), *fSec))
 	case z:
 		var (
-			dkBytes []byte
-			err     error
+			dk  kf.PrivateKey
+			err error
 		)
 		if stdinTerm {
 			if argc < 3 {
 				usage()
 				return
 			}
-			dkBytes, err = hex.DecodeString(os.Args[2])
-			check(err)
+			dk, err = hexPrivateKey(kf.MLKEM768, os.Args[2])
 		} else {
-			dkBytes = make([]byte, mlkem.SeedSize)
-			_, err = io.ReadFull(os.Stdin, dkBytes)
-			check(err)
+			dk, err = readPrivateKey(kf.MLKEM768)
 		}
-		dk, err := mlkem.NewDecapsulationKey768(dkBytes)
 		check(err)
-		ekBytes := dk.EncapsulationKey().Bytes()
-		if stdoutTerm {
-			fmt.Printf("%-5s%x\n%-5s%x\n", "priv", dkBytes, "pub", ekBytes)
-		} else {
-			os.Stdout.Write(ekBytes)
-			printf("%-5s%x\n%-5s%x\n", "priv", dkBytes, "pub", ekBytes)
-		}
+		check(writePublicKey(dk))
 	case e:
 		var (
-			ekBytes []byte
-			err     error
+			ek  kf.PublicKey
+			err error
 		)
 		if stdinTerm {
 			if argc < 3 {
 				usage()
 				return
 			}
-			ekBytes, err = hex.DecodeString(os.Args[2])
-			check(err)
+			ek, err = hexPublicKey(kf.MLKEM768, os.Args[2])
 		} else {
-			ekBytes = make([]byte, mlkem.EncapsulationKeySize768)
-			_, err = io.ReadFull(os.Stdin, ekBytes)
-			check(err)
+			ek, err = readPublicKey(kf.MLKEM768)
 		}
-		ek, err := mlkem.NewEncapsulationKey768(ekBytes)
 		check(err)
-		sk, ct := ek.Encapsulate()
+		sk, ct := ek.(*xp.KEMPublicKey).Encapsulate()
 		if stdoutTerm {
 			fmt.Printf("%-3s%x\n%-3s%x\n", "sk", sk, "ct", ct)
 		} else {
@@ -154,45 +648,44 @@ func main() {
 		}
 	case d:
 		var (
-			dkBytes, ct []byte
-			err         error
+			dk  kf.PrivateKey
+			ct  []byte
+			err error
 		)
 		if stdinTerm {
 			if argc < 4 {
 				usage()
 				return
 			}
-			dkBytes, err = hex.DecodeString(os.Args[2])
+			dk, err = hexPrivateKey(kf.MLKEM768, os.Args[2])
 			check(err)
 			ct, err = hex.DecodeString(os.Args[3])
 			check(err)
 		} else {
-			if argc > 3 {
-				dkOrCtBytes, err := hex.DecodeString(os.Args[2])
+			if argc > 2 {
+				var dkOrCt []byte
+				dkOrCt, err = hex.DecodeString(os.Args[2])
 				check(err)
-				if len(dkOrCtBytes) == mlkem.SeedSize {
-					dkBytes = dkOrCtBytes
-					ct = make([]byte, mlkem.CiphertextSize768)
-					_, err = io.ReadFull(os.Stdin, ct)
+				if kf.PeekPEM(stdin) || len(dkOrCt) == xp.KEMCiphertextSize {
+					dk, err = readPrivateKey(kf.MLKEM768)
 					check(err)
+					ct = dkOrCt
 				} else {
-					dkBytes = make([]byte, mlkem.SeedSize)
-					_, err = io.ReadFull(os.Stdin, dkBytes)
+					dk, err = parsePrivateKey(kf.MLKEM768, dkOrCt)
+					check(err)
+					ct = make([]byte, xp.KEMCiphertextSize)
+					_, err = io.ReadFull(stdin, ct)
 					check(err)
-					ct = dkOrCtBytes
 				}
 			} else {
-				dkBytes = make([]byte, mlkem.SeedSize)
-				_, err = io.ReadFull(os.Stdin, dkBytes)
+				dk, err = readPrivateKey(kf.MLKEM768)
 				check(err)
-				ct = make([]byte, mlkem.CiphertextSize768)
-				_, err = io.ReadFull(os.Stdin, ct)
+				ct = make([]byte, xp.KEMCiphertextSize)
+				_, err = io.ReadFull(stdin, ct)
 				check(err)
 			}
 		}
-		dk, err := mlkem.NewDecapsulationKey768(dkBytes)
-		check(err)
-		sk, err := dk.Decapsulate(ct)
+		sk, err := dk.(*xp.KEMPrivateKey).Decapsulate(ct)
 		check(err)
 		if stdoutTerm {
 			fmt.Printf("%-3s%x\n%-3s%x\n", "sk", sk, "ct", ct)
@@ -200,125 +693,1100 @@ func main() {
 			os.Stdout.Write(sk)
 			printf("%-3s%x\n%-3s%x\n", "sk", sk, "ct", ct)
 		}
-	case p:
-		private, public, err := xp.P()
-		check(err)
-		if stdoutTerm {
-			fmt.Printf("%-5s%x\n%-5s%x\n", "priv", private, "pub", public)
-		} else {
-			os.Stdout.Write(private)
-			printf("%-5s%x\n%-5s%x\n", "priv", private, "pub", public)
-		}
-	case x:
+	case x, x256, x384, x521:
+		algorithm := algorithms[os.Args[1]]
 		var (
-			scalar, point []byte
-			err           error
+			private kf.PrivateKey
+			public  kf.PublicKey
+			err     error
 		)
 		if stdinTerm {
 			if argc < 3 {
 				usage()
 				return
 			}
-			scalar, err = hex.DecodeString(os.Args[2])
+			private, err = hexPrivateKey(algorithm, os.Args[2])
 			check(err)
 			if argc > 3 {
-				point, err = hex.DecodeString(os.Args[3])
+				public, err = hexPublicKey(algorithm, os.Args[3])
 				check(err)
 			}
 		} else {
-			scalar = make([]byte, xp.Size)
-			_, err = io.ReadFull(os.Stdin, scalar)
+			private, err = readPrivateKey(algorithm)
 			check(err)
 			if argc > 2 {
-				point, err = hex.DecodeString(os.Args[2])
+				public, err = hexPublicKey(algorithm, os.Args[2])
 				check(err)
 			}
 		}
-		product, err := xp.X(scalar, point)
-		check(err)
-		if stdoutTerm {
-			fmt.Printf("%x\n", product)
+		var product []byte
+		if public == nil {
+			product = private.(*xp.PrivateKey).PublicKey().Bytes()
 		} else {
-			os.Stdout.Write(product)
-			printf("%x\n", product)
+			product, err = private.(*xp.PrivateKey).ECDH(public.(*xp.PublicKey))
+			check(err)
 		}
-	case g:
-		private, public, err := sv.G()
-		check(err)
-		if stdoutTerm {
-			fmt.Printf("%-5s%x\n%-5s%x\n", "priv", private, "pub", public)
+		writeBytes(product)
+	case k:
+		fs := newFlagSet(k, "[shared_hex] <public_hex> <public_hex>")
+		fHash := fs.Int("h", int(xp.DefaultHash), fmt.Sprintf("%s (%s)", geheim.HashDesc, geheim.HashString))
+		fSize := fs.Int("n", xp.DefaultKeySize, "key `size`")
+		fLabel := fs.String("l", "", "context `label`")
+		check(fs.Parse(os.Args[2:]))
+		args := fs.Args()
+		var (
+			secret, publicA, publicB []byte
+			err                      error
+		)
+		if stdinTerm {
+			if len(args) < 3 {
+				fs.Usage()
+				return
+			}
+			secret, err = hex.DecodeString(args[0])
+			check(err)
+			args = args[1:]
 		} else {
-			os.Stdout.Write(private)
-			printf("%-5s%x\n%-5s%x\n", "priv", private, "pub", public)
+			if len(args) < 2 {
+				fs.Usage()
+				return
+			}
+			secret, err = io.ReadAll(stdin)
+			check(err)
 		}
+		publicA, err = hexPublic(args[0])
+		check(err)
+		publicB, err = hexPublic(args[1])
+		check(err)
+		key, err := xp.K(geheim.Hash(*fHash), secret, publicA, publicB, *fLabel, *fSize)
+		check(err)
+		writeBytes(key)
 	case s:
+		fs := newFlagSet(s, "[message] [private_hex] > signature.bin")
+		fMode, fContext := modeFlags(fs)
+		fDetached := fs.Bool("d", false, "signature file")
+		fComment := fs.String("t", fmt.Sprintf("timestamp:%d\thashed", time.Now().Unix()), "trusted `comment`")
+		check(fs.Parse(os.Args[2:]))
 		var (
-			message, private []byte
-			err              error
+			message io.Reader
+			private kf.PrivateKey
+			err     error
 		)
 		if stdinTerm {
-			if argc < 4 {
-				usage()
+			if fs.NArg() < 2 {
+				fs.Usage()
 				return
 			}
-			message = []byte(os.Args[2])
-			private, err = hex.DecodeString(os.Args[3])
+			message = strings.NewReader(fs.Arg(0))
+			private, err = hexPrivateKey(kf.Ed25519, fs.Arg(1))
 			check(err)
 		} else {
-			private = make([]byte, sv.PrivateSize)
-			_, err = io.ReadFull(os.Stdin, private)
+			private, err = readPrivateKey(kf.Ed25519)
 			check(err)
-			if argc > 2 {
-				message = []byte(os.Args[2])
+			if fs.NArg() > 0 {
+				message = strings.NewReader(fs.Arg(0))
 			} else {
-				message, err = io.ReadAll(os.Stdin)
-				check(err)
+				message = stdin
 			}
 		}
-		signature, err := sv.S(message, private)
-		check(err)
-		if stdoutTerm {
-			fmt.Printf("%x\n", signature)
-		} else {
-			os.Stdout.Write(signature)
-			printf("%x\n", signature)
+		if *fDetached {
+			check(checkDetached(sv.Mode(*fMode), *fContext))
+			public, err := kf.Public(private)
+			check(err)
+			fp, err := kf.Fingerprint(public)
+			check(err)
+			signature, err := ds.Sign(private.(*sv.PrivateKey), message, *fComment, fmt.Sprintf("signature from xp key %x", fp))
+			check(err)
+			data, err := signature.MarshalText()
+			check(err)
+			os.Stdout.Write(data)
+			return
 		}
+		signature, err := private.(*sv.PrivateKey).SignMode(sv.Mode(*fMode), message, *fContext)
+		check(err)
+		writeBytes(signature)
 	case v:
+		fs := newFlagSet(v, "[message] <public_hex> [signature_hex]")
+		fMode, fContext := modeFlags(fs)
+		fDetached := fs.String("d", "", "signature file `path`")
+		check(fs.Parse(os.Args[2:]))
+		if *fDetached != "" {
+			check(checkDetached(sv.Mode(*fMode), *fContext))
+			var message io.Reader
+			switch {
+			case fs.NArg() > 1:
+				message = strings.NewReader(fs.Arg(0))
+			case fs.NArg() > 0 && !stdinTerm:
+				message = stdin
+			default:
+				fs.Usage()
+				return
+			}
+			data, err := os.ReadFile(*fDetached)
+			check(err)
+			signature, err := ds.ParseSignature(data)
+			check(err)
+			public, err := detachedPublicKey(fs.Arg(fs.NArg() - 1))
+			check(err)
+			check(signature.Verify(public, message))
+			fmt.Println(signature.TrustedComment)
+			return
+		}
 		var (
-			message, public, signature []byte
-			err                        error
+			message   io.Reader
+			signature []byte
+			public    kf.PublicKey
+			err       error
 		)
 		if stdinTerm {
-			if argc < 5 {
-				usage()
+			if fs.NArg() < 3 {
+				fs.Usage()
 				return
 			}
-			message = []byte(os.Args[2])
-			public, err = hex.DecodeString(os.Args[3])
+			message = strings.NewReader(fs.Arg(0))
+			public, err = hexPublicKey(kf.Ed25519, fs.Arg(1))
 			check(err)
-			signature, err = hex.DecodeString(os.Args[4])
+			signature, err = hex.DecodeString(fs.Arg(2))
 			check(err)
 		} else {
-			if argc < 3 {
-				usage()
+			if fs.NArg() < 1 {
+				fs.Usage()
 				return
 			}
 			signature = make([]byte, sv.SignatureSize)
-			_, err = io.ReadFull(os.Stdin, signature)
+			_, err = io.ReadFull(stdin, signature)
 			check(err)
-			if argc > 3 {
-				message = []byte(os.Args[2])
-				public, err = hex.DecodeString(os.Args[3])
+			if fs.NArg() > 1 {
+				message = strings.NewReader(fs.Arg(0))
+				public, err = hexPublicKey(kf.Ed25519, fs.Arg(1))
 				check(err)
 			} else {
-				message, err = io.ReadAll(os.Stdin)
+				message = stdin
+				public, err = hexPublicKey(kf.Ed25519, fs.Arg(0))
+				check(err)
+			}
+		}
+		check(public.(*sv.PublicKey).VerifyMode(sv.Mode(*fMode), message, signature, *fContext))
+	case c, u:
+		fs := newFlagSet(os.Args[1], "< private.key > private.key")
+		var (
+			fKDF *int
+			fSec *int
+		)
+		if os.Args[1] == c {
+			_, fKDF, fSec = protectFlags(fs)
+		}
+		check(fs.Parse(os.Args[2:]))
+		if stdinTerm || stdoutTerm {
+			fs.Usage()
+			return
+		}
+		data, err := kf.ReadKey(stdin, 0)
+		check(err)
+		key, err := parsePrivateKey(0, data)
+		check(err)
+		var passphrase []byte
+		if os.Args[1] == c {
+			passphrase, err = readNewPassphrase()
+			check(err)
+		}
+		if passphrase != nil {
+			data, err = kf.MarshalEncryptedPrivateKey(key, passphrase, geheim.KDF(*fKDF), *fSec)
+		} else {
+			data, err = kf.MarshalPrivateKey(key)
+		}
+		check(err)
+		os.Stdout.Write(data)
+	case f:
+		var (
+			public kf.PublicKey
+			err    error
+		)
+		if stdinTerm {
+			if argc < 3 {
+				usage()
+				return
+			}
+			public, err = kf.DecodePublicKey(os.Args[2])
+			check(err)
+		} else {
+			data, err := kf.ReadKey(stdin, 0)
+			check(err)
+			if public, err = kf.ParsePublicKey(data); err != nil {
+				private, err := parsePrivateKey(0, data)
 				check(err)
-				public, err = hex.DecodeString(os.Args[2])
+				public, err = kf.Public(private)
 				check(err)
 			}
 		}
-		err = sv.V(message, public, signature)
+		check(printKey(os.Stdout, nil, public))
+	case l:
+		store, err := ks.Default()
+		check(err)
+		entries, err := store.List()
+		check(err)
+		for _, entry := range entries {
+			kind := "contact"
+			if entry.Identity {
+				kind = "identity"
+			}
+			fmt.Printf("%-16s %-9s %-11s %x\n", entry.Name, kind, kf.AlgorithmNames[entry.Algorithm], entry.Fingerprint)
+		}
+	case i:
+		fs := newFlagSet(i, "<name> [encoded_public] < key")
+		_, fKDF, fSec := protectFlags(fs)
+		check(fs.Parse(os.Args[2:]))
+		args := fs.Args()
+		if len(args) < 1 || (stdinTerm && len(args) < 2) {
+			fs.Usage()
+			return
+		}
+		store, err := ks.Default()
+		check(err)
+		name := args[0]
+		if len(args) > 1 {
+			public, err := kf.DecodePublicKey(args[1])
+			check(err)
+			check(store.ImportContact(name, public))
+			return
+		}
+		data, err := kf.ReadKey(stdin, 0)
+		check(err)
+		if public, err := kf.ParsePublicKey(data); err == nil {
+			check(store.ImportContact(name, public))
+			return
+		}
+		encrypted := kf.IsEncrypted(data)
+		private, err := parsePrivateKey(0, data)
+		check(err)
+		if !encrypted {
+			passphrase, err := readNewPassphrase()
+			check(err)
+			data, err = kf.MarshalEncryptedPrivateKey(private, passphrase, geheim.KDF(*fKDF), *fSec)
+			check(err)
+		}
+		public, err := kf.Public(private)
 		check(err)
+		check(store.ImportIdentity(name, data, public))
+	case o:
+		fs := newFlagSet(o, "<name>")
+		fPrivate := fs.Bool("p", false, "export protected private key")
+		check(fs.Parse(os.Args[2:]))
+		if fs.NArg() < 1 {
+			fs.Usage()
+			return
+		}
+		store, err := ks.Default()
+		check(err)
+		name := fs.Arg(0)
+		var data []byte
+		if *fPrivate {
+			data, err = store.Identity(name)
+			check(err)
+		} else {
+			public, err := store.Public(name)
+			check(err)
+			if stdoutTerm {
+				check(printKey(os.Stdout, nil, public))
+				return
+			}
+			data, err = kf.MarshalPublicKey(public)
+			check(err)
+		}
+		os.Stdout.Write(data)
+	case r:
+		if argc < 3 {
+			usage()
+			return
+		}
+		store, err := ks.Default()
+		check(err)
+		check(store.Delete(os.Args[2]))
+	case t:
+		fs := newFlagSet(t, "[secret] > shares.txt")
+		fThreshold := fs.Int("m", 2, "`threshold`")
+		fShares := fs.Int("n", 3, "`shares`")
+		fLine := fs.Bool("l", false, "trim the trailing newline from stdin")
+		check(fs.Parse(os.Args[2:]))
+		var (
+			secret []byte
+			err    error
+		)
+		if stdinTerm {
+			if fs.NArg() < 1 {
+				fs.Usage()
+				return
+			}
+			secret = []byte(fs.Arg(0))
+		} else {
+			secret, err = io.ReadAll(stdin)
+			check(err)
+			if *fLine {
+				secret = bytes.TrimSuffix(bytes.TrimSuffix(secret, []byte("\n")), []byte("\r"))
+			}
+		}
+		shares, err := ss.Split(secret, *fThreshold, *fShares)
+		check(err)
+		for _, share := range shares {
+			fmt.Println(share)
+		}
+	case j:
+		if stdinTerm {
+			usage()
+			return
+		}
+		var shares []ss.Share
+		scanner := bufio.NewScanner(stdin)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var share ss.Share
+			check(share.UnmarshalText([]byte(line)))
+			shares = append(shares, share)
+		}
+		check(scanner.Err())
+		secret, err := ss.Combine(shares)
+		check(err)
+		if stdoutTerm {
+			fmt.Printf("%x\n", secret)
+		} else {
+			os.Stdout.Write(secret)
+		}
+	case m:
+		if stdinTerm {
+			usage()
+			return
+		}
+		data, err := kf.ReadKey(stdin, 0)
+		check(err)
+		key, err := parsePrivateKey(0, data)
+		check(err)
+		algorithm, err := kf.AlgorithmOf(key)
+		check(err)
+		seed, err := kf.Seed(key)
+		check(err)
+		words, err := mn.Encode(seed)
+		check(err)
+		fmt.Println(strings.Join(words, " "))
+		printf("%-5s%s(%d)\n", "alg", kf.AlgorithmNames[algorithm], algorithm)
+	case n:
+		fs := newFlagSet(n, "[word]... > private.key")
+		fAlgorithm := fs.Int("a", int(kf.Ed25519), fmt.Sprintf("algorithm (%s)", kf.AlgorithmString))
+		fProtect, fKDF, fSec := protectFlags(fs)
+		check(fs.Parse(os.Args[2:]))
+		words := fs.Args()
+		if len(words) == 0 {
+			if stdinTerm {
+				fs.Usage()
+				return
+			}
+			data, err := io.ReadAll(stdin)
+			check(err)
+			words = mn.Parse(string(data))
+		} else {
+			words = mn.Parse(strings.Join(words, " "))
+		}
+		seed, err := mn.Decode(words)
+		check(err)
+		if size := kf.SeedSizes[kf.Algorithm(*fAlgorithm)]; len(seed) > size && len(bytes.TrimRight(seed[size:], "\x00")) == 0 {
+			seed = seed[:size]
+		}
+		key, err := kf.NewKeyFromSeed(kf.Algorithm(*fAlgorithm), seed)
+		check(err)
+		var passphrase []byte
+		if *fProtect {
+			passphrase, err = readNewPassphrase()
+			check(err)
+		}
+		check(writePrivateKey(key, passphrase, geheim.KDF(*fKDF), *fSec))
+	case h:
+		fs := newFlagSet(h, "<path> [seed_hex] > private.key")
+		fAlgorithm := fs.Int("a", int(kf.Ed25519), fmt.Sprintf("algorithm (%d:%s, %d:%s)", kf.Ed25519, kf.AlgorithmNames[kf.Ed25519], kf.X25519, kf.AlgorithmNames[kf.X25519]))
+		fWords := fs.Bool("w", false, "mnemonic seed")
+		fSalt := fs.String("s", "", "mnemonic `passphrase`")
+		fProtect, fKDF, fSec := protectFlags(fs)
+		check(fs.Parse(os.Args[2:]))
+		if fs.NArg() < 1 {
+			fs.Usage()
+			return
+		}
+		path := fs.Arg(0)
+		var (
+			seed []byte
+			err  error
+		)
+		switch {
+		case *fWords:
+			words := fs.Args()[1:]
+			if len(words) == 0 {
+				if stdinTerm {
+					fs.Usage()
+					return
+				}
+				data, err := io.ReadAll(stdin)
+				check(err)
+				words = mn.Parse(string(data))
+			} else {
+				words = mn.Parse(strings.Join(words, " "))
+			}
+			_, err = mn.Decode(words)
+			check(err)
+			seed, err = mn.Seed(words, *fSalt)
+		case stdinTerm:
+			if fs.NArg() < 2 {
+				fs.Usage()
+				return
+			}
+			seed, err = hex.DecodeString(fs.Arg(1))
+		default:
+			seed, err = io.ReadAll(stdin)
+		}
+		check(err)
+		var key kf.PrivateKey
+		switch kf.Algorithm(*fAlgorithm) {
+		case kf.Ed25519:
+			key, err = sv.Derive(seed, path)
+		case kf.X25519:
+			key, err = xp.Derive(seed, path)
+		default:
+			err = kf.ErrAlgorithm
+		}
+		check(err)
+		var passphrase []byte
+		if *fProtect {
+			passphrase, err = readNewPassphrase()
+			check(err)
+		}
+		check(writePrivateKey(key, passphrase, geheim.KDF(*fKDF), *fSec))
+	case y:
+		fs := newFlagSet(y, "[key_hex] > x25519.key")
+		fProtect, fKDF, fSec := protectFlags(fs)
+		check(fs.Parse(os.Args[2:]))
+		var (
+			private kf.PrivateKey
+			public  kf.PublicKey
+			err     error
+		)
+		if stdinTerm {
+			if fs.NArg() < 1 {
+				fs.Usage()
+				return
+			}
+			if b, _ := hex.DecodeString(fs.Arg(0)); len(b) == sv.PrivateSize {
+				private, err = hexPrivateKey(kf.Ed25519, fs.Arg(0))
+			} else {
+				public, err = hexPublicKey(kf.Ed25519, fs.Arg(0))
+			}
+		} else {
+			var data []byte
+			data, err = io.ReadAll(stdin)
+			check(err)
+			if kf.IsPEM(data) {
+				if public, err = kf.ParsePublicKey(data); err == nil {
+					err = checkAlgorithm(kf.Ed25519, public)
+				} else {
+					private, err = parsePrivateKey(kf.Ed25519, data)
+				}
+			} else if len(data) == sv.PublicSize {
+				public, err = parsePublicKey(kf.Ed25519, data)
+			} else {
+				private, err = parsePrivateKey(kf.Ed25519, data)
+			}
+		}
+		check(err)
+		if private != nil {
+			key, err := xp.NewEd25519PrivateKey(private.(*sv.PrivateKey))
+			check(err)
+			var passphrase []byte
+			if *fProtect {
+				passphrase, err = readNewPassphrase()
+				check(err)
+			}
+			check(writePrivateKey(key, passphrase, geheim.KDF(*fKDF), *fSec))
+			return
+		}
+		key, err := xp.NewEd25519PublicKey(public.(*sv.PublicKey))
+		check(err)
+		if stdoutTerm {
+			check(printKey(os.Stdout, nil, key))
+			return
+		}
+		data, err := kf.MarshalPublicKey(key)
+		check(err)
+		os.Stdout.Write(data)
+		check(printKey(os.Stderr, nil, key))
+	case w:
+		var (
+			public kf.PublicKey
+			err    error
+		)
+		if stdinTerm {
+			if argc < 3 {
+				usage()
+				return
+			}
+			public, err = hexPublicKey(kf.Ed25519, os.Args[2])
+		} else {
+			var data []byte
+			data, err = kf.ReadKey(stdin, 0)
+			check(err)
+			if public, err = kf.ParsePublicKey(data); err != nil {
+				var private kf.PrivateKey
+				private, err = parsePrivateKey(kf.Ed25519, data)
+				check(err)
+				public, err = kf.Public(private)
+			} else {
+				err = checkAlgorithm(kf.Ed25519, public)
+			}
+		}
+		check(err)
+		key, err := ds.NewPublicKey(public.(*sv.PublicKey))
+		check(err)
+		data, err := key.MarshalText()
+		check(err)
+		os.Stdout.Write(data)
+	case a:
+		fs := newFlagSet(a, "<dir> [private_hex] > manifest")
+		fHash := fs.Int("h", int(mf.DefaultHash), fmt.Sprintf("%s (%s)", geheim.HashDesc, geheim.HashString))
+		check(fs.Parse(os.Args[2:]))
+		var (
+			private kf.PrivateKey
+			err     error
+		)
+		if stdinTerm {
+			if fs.NArg() < 2 {
+				fs.Usage()
+				return
+			}
+			private, err = hexPrivateKey(kf.Ed25519, fs.Arg(1))
+		} else {
+			if fs.NArg() < 1 {
+				fs.Usage()
+				return
+			}
+			private, err = readPrivateKey(kf.Ed25519)
+		}
+		check(err)
+		manifest, err := mf.Build(os.DirFS(fs.Arg(0)), geheim.Hash(*fHash))
+		check(err)
+		data, err := manifest.Sign(private.(*sv.PrivateKey))
+		check(err)
+		os.Stdout.Write(data)
+	case b:
+		if stdinTerm || argc < 4 {
+			usage()
+			return
+		}
+		public, err := hexPublicKey(kf.Ed25519, os.Args[3])
+		check(err)
+		data, err := io.ReadAll(stdin)
+		check(err)
+		manifest, err := mf.Parse(data, public.(*sv.PublicKey))
+		check(err)
+		differences, err := manifest.Verify(os.DirFS(os.Args[2]))
+		check(err)
+		for _, difference := range differences {
+			fmt.Println(difference)
+		}
+		if len(differences) > 0 {
+			check(mf.ErrModified)
+		}
+	case C:
+		fs := newFlagSet(C, "<public_hex> [private_hex] > certificate.pem")
+		fName := fs.String("n", "", "`name`")
+		fUsage := fs.Int("u", int(kc.UsageSign), fmt.Sprintf("usage (%s)", kc.UsageString))
+		fNotBefore := fs.String("b", "", "not before `time` (RFC 3339)")
+		fValidity := fs.Duration("d", 365*24*time.Hour, "validity `duration`")
+		check(fs.Parse(os.Args[2:]))
+		if fs.NArg() < 1 || stdinTerm && fs.NArg() < 2 {
+			fs.Usage()
+			return
+		}
+		subject, err := hexPublicKey(kf.Ed25519, fs.Arg(0))
+		check(err)
+		var issuer kf.PrivateKey
+		if stdinTerm {
+			issuer, err = hexPrivateKey(kf.Ed25519, fs.Arg(1))
+		} else {
+			issuer, err = readPrivateKey(kf.Ed25519)
+		}
+		check(err)
+		notBefore := time.Now()
+		if *fNotBefore != "" {
+			notBefore, err = time.Parse(time.RFC3339, *fNotBefore)
+			check(err)
+		}
+		certificate, err := kc.Certify(issuer.(*sv.PrivateKey), subject.(*sv.PublicKey), *fName, notBefore, notBefore.Add(*fValidity), kc.Usage(*fUsage))
+		check(err)
+		data, err := certificate.MarshalPEM()
+		check(err)
+		os.Stdout.Write(data)
+	case R:
+		fs := newFlagSet(R, "[public_hex] [private_hex] > revocation.pem")
+		fReason := fs.String("r", "", "`reason`")
+		check(fs.Parse(os.Args[2:]))
+		var (
+			issuer kf.PrivateKey
+			key    kf.PublicKey
+			err    error
+		)
+		if stdinTerm {
+			if fs.NArg() < 2 {
+				fs.Usage()
+				return
+			}
+			issuer, err = hexPrivateKey(kf.Ed25519, fs.Arg(1))
+		} else {
+			issuer, err = readPrivateKey(kf.Ed25519)
+		}
+		check(err)
+		if fs.NArg() > 0 {
+			key, err = hexPublicKey(kf.Ed25519, fs.Arg(0))
+			check(err)
+		} else {
+			key = issuer.(*sv.PrivateKey).PublicKey()
+		}
+		revocation, err := kc.Revoke(issuer.(*sv.PrivateKey), key.(*sv.PublicKey), time.Now(), *fReason)
+		check(err)
+		data, err := revocation.MarshalPEM()
+		check(err)
+		os.Stdout.Write(data)
+	case T:
+		fs := newFlagSet(T, "<public_hex> [message] [signature_hex]")
+		verifier := new(kc.Verifier)
+		fs.Func("r", "trusted root `key`, path or name", func(s string) error {
+			key, err := hexPublicKey(kf.Ed25519, s)
+			if err != nil {
+				return err
+			}
+			verifier.Roots = append(verifier.Roots, key.(*sv.PublicKey))
+			return nil
+		})
+		fs.Func("c", "certificate or revocation `path`", func(s string) error {
+			data, err := os.ReadFile(s)
+			if err != nil {
+				return err
+			}
+			certificates, revocations, err := kc.Parse(data)
+			if err != nil {
+				return err
+			}
+			verifier.Certificates = append(verifier.Certificates, certificates...)
+			verifier.Revocations = append(verifier.Revocations, revocations...)
+			return nil
+		})
+		fUsage := fs.Int("u", int(kc.UsageSign), fmt.Sprintf("usage (%s)", kc.UsageString))
+		check(fs.Parse(os.Args[2:]))
+		if fs.NArg() < 1 {
+			fs.Usage()
+			return
+		}
+		key, err := hexPublicKey(kf.Ed25519, fs.Arg(0))
+		check(err)
+		public := key.(*sv.PublicKey)
+		var (
+			message   io.Reader
+			signature []byte
+		)
+		switch {
+		case fs.NArg() > 2:
+			message = strings.NewReader(fs.Arg(1))
+			signature, err = hex.DecodeString(fs.Arg(2))
+			check(err)
+		case !stdinTerm:
+			message = stdin
+			signature = make([]byte, sv.SignatureSize)
+			_, err = io.ReadFull(stdin, signature)
+			check(err)
+		}
+		want := kc.Usage(*fUsage)
+		if message != nil {
+			want |= kc.UsageSign
+		}
+		certificates, err := verifier.Verify(public, want, time.Now())
+		check(err)
+		if message != nil {
+			check(public.VerifyMode(sv.Pure, message, signature, ""))
+		}
+		for _, certificate := range certificates {
+			enc, err := kf.EncodePublicKey(certificate.Subject)
+			check(err)
+			fmt.Printf("%s %q %s %s\n", enc, certificate.Name, certificate.Usage, certificate.NotAfter.Format(time.RFC3339))
+		}
+		root := public
+		if len(certificates) > 0 {
+			root = certificates[len(certificates)-1].Issuer
+		}
+		enc, err := kf.EncodePublicKey(root)
+		check(err)
+		fmt.Printf("%s root\n", enc)
+	case S:
+		fs := newFlagSet(S, "[message] [private_hex] > message.sig")
+		fNamespace := fs.String("n", "file", "`namespace`")
+		fHash := fs.String("h", sv.DefaultSSHSigHash, fmt.Sprintf("hash (%s, %s)", sv.HashSHA256, sv.HashSHA512))
+		check(fs.Parse(os.Args[2:]))
+		var (
+			message io.Reader
+			private kf.PrivateKey
+			err     error
+		)
+		if stdinTerm {
+			if fs.NArg() < 2 {
+				fs.Usage()
+				return
+			}
+			message = strings.NewReader(fs.Arg(0))
+			private, err = hexPrivateKey(kf.Ed25519, fs.Arg(1))
+			check(err)
+		} else {
+			private, err = readPrivateKey(kf.Ed25519)
+			check(err)
+			if fs.NArg() > 0 {
+				message = strings.NewReader(fs.Arg(0))
+			} else {
+				message = stdin
+			}
+		}
+		signature, err := private.(*sv.PrivateKey).SignSSH(message, *fNamespace, *fHash)
+		check(err)
+		data, err := signature.MarshalText()
+		check(err)
+		os.Stdout.Write(data)
+	case V:
+		fs := newFlagSet(V, "[message] [public_hex]")
+		fNamespace := fs.String("n", "", "`namespace` (default file, or git with -g)")
+		fSignature := fs.String("s", "", "signature `path`")
+		fAllowed := fs.String("f", "", "allowed signers `path`")
+		fPrincipal := fs.String("I", "", "`principal`")
+		fGit := fs.Bool("g", false, "git commit or tag on stdin")
+		check(fs.Parse(os.Args[2:]))
+		namespace := *fNamespace
+		if namespace == "" {
+			namespace = "file"
+			if *fGit {
+				namespace = "git"
+			}
+		}
+		var (
+			message io.Reader
+			data    []byte
+			err     error
+		)
+		args := fs.Args()
+		switch {
+		case *fGit:
+			if stdinTerm {
+				fs.Usage()
+				return
+			}
+			object, err := io.ReadAll(stdin)
+			check(err)
+			var payload []byte
+			payload, data, err = splitGitSignature(object)
+			check(err)
+			message = bytes.NewReader(payload)
+		case *fSignature == "":
+			fs.Usage()
+			return
+		default:
+			data, err = os.ReadFile(*fSignature)
+			check(err)
+			keys := 1
+			if *fAllowed != "" {
+				keys = 0
+			}
+			if stdinTerm || len(args) > keys {
+				if len(args) == 0 {
+					fs.Usage()
+					return
+				}
+				message = strings.NewReader(args[0])
+				args = args[1:]
+			} else {
+				message = stdin
+			}
+		}
+		if *fAllowed == "" && len(args) < 1 {
+			fs.Usage()
+			return
+		}
+		signature := new(sv.SSHSignature)
+		check(signature.UnmarshalText(data))
+		check(signature.Verify(message, namespace))
+		var principals []string
+		if *fAllowed != "" {
+			data, err := os.ReadFile(*fAllowed)
+			check(err)
+			signers, err := sv.ParseAllowedSigners(data)
+			check(err)
+			if *fPrincipal != "" {
+				check(signature.VerifyAllowed(signers, *fPrincipal, time.Now()))
+				principals = []string{*fPrincipal}
+			} else if principals = sv.FindPrincipals(signers, signature.PublicKey, namespace, time.Now()); len(principals) == 0 {
+				check(sv.ErrPublicKey)
+			}
+		} else {
+			public, err := hexPublicKey(kf.Ed25519, args[0])
+			check(err)
+			if !public.Equal(signature.PublicKey) {
+				check(sv.ErrPublicKey)
+			}
+			enc, err := kf.EncodePublicKey(public)
+			check(err)
+			principals = []string{enc}
+		}
+		key, err := ssh.NewPublicKey(ed25519.PublicKey(signature.PublicKey.Bytes()))
+		check(err)
+		for _, principal := range principals {
+			fmt.Printf("Good %q signature for %s with ED25519 key %s\n", namespace, principal, ssh.FingerprintSHA256(key))
+		}
+	case K:
+		fs := newFlagSet(K, "[public_hex] > key.jwk")
+		fAlgorithm := fs.Int("a", int(kf.Ed25519), fmt.Sprintf("algorithm (%d:%s, %d:%s)", kf.Ed25519, kf.AlgorithmNames[kf.Ed25519], kf.X25519, kf.AlgorithmNames[kf.X25519]))
+		fPublic := fs.Bool("P", false, "public only")
+		fProtect, fKDF, fSec := protectFlags(fs)
+		check(fs.Parse(os.Args[2:]))
+		algorithm := kf.Algorithm(*fAlgorithm)
+		var (
+			key any
+			err error
+		)
+		switch {
+		case fs.NArg() > 0:
+			key, err = hexPublicKey(algorithm, fs.Arg(0))
+		case stdinTerm:
+			fs.Usage()
+			return
+		default:
+			if b, _ := stdin.Peek(1); jw.IsJSON(b) {
+				j, err := readJWK()
+				check(err)
+				if j.D == "" || *fPublic {
+					public, err := j.PublicKey()
+					check(err)
+					if stdoutTerm {
+						check(printKey(os.Stdout, nil, public))
+						return
+					}
+					data, err := kf.MarshalPublicKey(public)
+					check(err)
+					os.Stdout.Write(data)
+					check(printKey(os.Stderr, nil, public))
+					return
+				}
+				private, err := j.PrivateKey()
+				check(err)
+				var passphrase []byte
+				if *fProtect {
+					passphrase, err = readNewPassphrase()
+					check(err)
+				}
+				check(writePrivateKey(private, passphrase, geheim.KDF(*fKDF), *fSec))
+				return
+			}
+			var data []byte
+			data, err = io.ReadAll(stdin)
+			check(err)
+			if kf.IsPEM(data) {
+				if key, err = kf.ParsePublicKey(data); err == nil {
+					err = checkAlgorithm(algorithm, key)
+				} else {
+					key, err = parsePrivateKey(algorithm, data)
+				}
+			} else {
+				key, err = parsePrivateKey(algorithm, data)
+			}
+		}
+		check(err)
+		j, err := jw.NewJWK(key)
+		check(err)
+		if *fPublic {
+			j = j.Public()
+		}
+		data, err := json.Marshal(j)
+		check(err)
+		fmt.Printf("%s\n", data)
+	case J:
+		fs := newFlagSet(J, "[message] [private_hex] > token.txt")
+		fJSON := fs.Bool("j", false, "json serialization")
+		fType := fs.String("t", "", "`type` header")
+		check(fs.Parse(os.Args[2:]))
+		var (
+			message []byte
+			private kf.PrivateKey
+			err     error
+		)
+		if stdinTerm {
+			if fs.NArg() < 2 {
+				fs.Usage()
+				return
+			}
+			message = []byte(fs.Arg(0))
+			private, err = jwkPrivateKey(kf.Ed25519, fs.Arg(1))
+			check(err)
+		} else {
+			private, err = readJWKPrivateKey(kf.Ed25519)
+			check(err)
+			if fs.NArg() > 0 {
+				message = []byte(fs.Arg(0))
+			} else {
+				message, err = io.ReadAll(stdin)
+				check(err)
+			}
+		}
+		header := jw.Header{}
+		if *fType != "" {
+			header["typ"] = *fType
+		}
+		var token []byte
+		if *fJSON {
+			token, err = jw.SignJSON([]*sv.PrivateKey{private.(*sv.PrivateKey)}, message, header)
+		} else {
+			var t string
+			t, err = jw.Sign(private.(*sv.PrivateKey), message, header)
+			token = []byte(t)
+		}
+		check(err)
+		fmt.Printf("%s\n", token)
+	case W:
+		if argc < 3 || argc < 4 && stdinTerm {
+			usage()
+			return
+		}
+		public, err := jwkPublicKey(kf.Ed25519, os.Args[2])
+		check(err)
+		var token []byte
+		if argc > 3 {
+			token = []byte(os.Args[3])
+		} else {
+			token, err = io.ReadAll(stdin)
+			check(err)
+		}
+		payload, _, err := jw.Verify(token, public.(*sv.PublicKey))
+		check(err)
+		os.Stdout.Write(payload)
+	case E:
+		if argc < 3 || argc < 4 && stdinTerm {
+			usage()
+			return
+		}
+		public, err := jwkPublicKey(kf.X25519, os.Args[2])
+		check(err)
+		var message []byte
+		if argc > 3 {
+			message = []byte(os.Args[3])
+		} else {
+			message, err = io.ReadAll(stdin)
+			check(err)
+		}
+		token, err := jw.Encrypt(public.(*xp.PublicKey), message, nil)
+		check(err)
+		fmt.Println(token)
+	case D:
+		var (
+			private kf.PrivateKey
+			token   []byte
+			err     error
+		)
+		switch {
+		case argc > 3:
+			private, err = jwkPrivateKey(kf.X25519, os.Args[2])
+			check(err)
+			token = []byte(os.Args[3])
+		case argc > 2 && !stdinTerm && strings.Count(os.Args[2], ".") == 4:
+			private, err = readJWKPrivateKey(kf.X25519)
+			check(err)
+			token = []byte(os.Args[2])
+		case argc > 2 && !stdinTerm:
+			private, err = jwkPrivateKey(kf.X25519, os.Args[2])
+			check(err)
+			token, err = io.ReadAll(stdin)
+			check(err)
+		default:
+			usage()
+			return
+		}
+		plaintext, _, err := jw.Decrypt(string(token), private.(*xp.PrivateKey))
+		check(err)
+		os.Stdout.Write(plaintext)
+	case X, Q:
+		fs := newFlagSet(os.Args[1], "[private_hex] > "+map[string]string{X: "certificate.pem", Q: "request.pem"}[os.Args[1]])
+		fSubject := fs.String("n", "", "subject `name`")
+		var sans []string
+		fs.Func("s", "subject alternative `name`", func(s string) error {
+			sans = append(sans, s)
+			return nil
+		})
+		var (
+			fNotBefore *string
+			fValidity  *time.Duration
+			fCA        *bool
+			fRequest   *string
+			fIssuer    *string
+		)
+		if os.Args[1] == X {
+			fNotBefore = fs.String("b", "", "not before `time` (RFC 3339)")
+			fValidity = fs.Duration("d", xc.DefaultValidity, "validity `duration`")
+			fCA = fs.Bool("a", false, "certificate authority")
+			fRequest = fs.String("r", "", "certificate request `path`")
+			fIssuer = fs.String("c", "", "issuer certificate `path`")
+		}
+		check(fs.Parse(os.Args[2:]))
+		var (
+			private kf.PrivateKey
+			err     error
+		)
+		if stdinTerm {
+			if fs.NArg() < 1 {
+				fs.Usage()
+				return
+			}
+			private, err = hexPrivateKey(kf.Ed25519, fs.Arg(0))
+		} else {
+			private, err = readPrivateKey(kf.Ed25519)
+		}
+		check(err)
+		key := private.(*sv.PrivateKey)
+		subject, err := xc.ParseName(*fSubject)
+		check(err)
+		alternatives, err := xc.ParseSANs(sans)
+		check(err)
+		if os.Args[1] == Q {
+			request, err := xc.CreateRequest(key, subject, alternatives)
+			check(err)
+			os.Stdout.Write(xc.EncodeRequest(request))
+			return
+		}
+		notBefore := time.Now()
+		if *fNotBefore != "" {
+			notBefore, err = time.Parse(time.RFC3339, *fNotBefore)
+			check(err)
+		}
+		notAfter := notBefore.Add(*fValidity)
+		var certificate *x509.Certificate
+		if *fRequest != "" {
+			if *fIssuer == "" {
+				fs.Usage()
+				return
+			}
+			data, err := os.ReadFile(*fRequest)
+			check(err)
+			request, err := xc.ParseRequest(data)
+			check(err)
+			data, err = os.ReadFile(*fIssuer)
+			check(err)
+			issuer, err := xc.ParseCertificate(data)
+			check(err)
+			certificate, err = xc.SignRequest(key, issuer, request, notBefore, notAfter, *fCA)
+			check(err)
+		} else {
+			certificate, err = xc.SelfSign(key, subject, alternatives, notBefore, notAfter, *fCA)
+			check(err)
+		}
+		os.Stdout.Write(xc.EncodeCertificate(certificate))
 	default:
 		usage()
 	}
@@ -0,0 +1,215 @@
+package ds
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/sv"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	AlgorithmPure   = "Ed"
+	AlgorithmHashed = "ED"
+)
+
+const (
+	KeyIDSize      = 8
+	MaxCommentSize = 1024
+
+	prefixUntrusted = "untrusted comment: "
+	prefixTrusted   = "trusted comment: "
+)
+
+var (
+	ErrAlgorithm = errors.New("ds: unsupported signature algorithm")
+	ErrComment   = errors.New("ds: invalid comment")
+	ErrFormat    = errors.New("ds: malformed signature file")
+	ErrKeyID     = errors.New("ds: key id mismatch")
+	ErrPublicKey = errors.New("ds: malformed public key")
+)
+
+type KeyID [KeyIDSize]byte
+
+func (id KeyID) String() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+type PublicKey struct {
+	KeyID KeyID
+	Key   *sv.PublicKey
+}
+
+type Signature struct {
+	Algorithm        string
+	KeyID            KeyID
+	Signature        []byte
+	UntrustedComment string
+	TrustedComment   string
+	GlobalSignature  []byte
+}
+
+func NewPublicKey(key *sv.PublicKey) (*PublicKey, error) {
+	fp, err := kf.Fingerprint(key)
+	if err != nil {
+		return nil, err
+	}
+	p := &PublicKey{Key: key}
+	copy(p.KeyID[:], fp)
+	return p, nil
+}
+
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	lines := splitLines(data)
+	if len(lines) > 0 && strings.HasPrefix(lines[0], prefixUntrusted) {
+		lines = lines[1:]
+	}
+	if len(lines) < 1 {
+		return nil, ErrPublicKey
+	}
+	b, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(b) != 2+KeyIDSize+sv.PublicSize || string(b[:2]) != AlgorithmPure {
+		return nil, ErrPublicKey
+	}
+	key, err := sv.NewPublicKey(b[2+KeyIDSize:])
+	if err != nil {
+		return nil, err
+	}
+	p := &PublicKey{Key: key}
+	copy(p.KeyID[:], b[2:])
+	return p, nil
+}
+
+func (p *PublicKey) String() string {
+	b := append([]byte(AlgorithmPure), p.KeyID[:]...)
+	return base64.StdEncoding.EncodeToString(append(b, p.Key.Bytes()...))
+}
+
+func (p *PublicKey) MarshalText() ([]byte, error) {
+	return fmt.Appendf(nil, "%sminisign public key %s\n%s\n", prefixUntrusted, p.KeyID, p), nil
+}
+
+func Sign(key *sv.PrivateKey, r io.Reader, trustedComment, untrustedComment string) (*Signature, error) {
+	if !validComment(trustedComment) || !validComment(untrustedComment) {
+		return nil, ErrComment
+	}
+	public, err := NewPublicKey(key.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	m, err := message(AlgorithmHashed, r)
+	if err != nil {
+		return nil, err
+	}
+	s := &Signature{
+		Algorithm:        AlgorithmHashed,
+		KeyID:            public.KeyID,
+		UntrustedComment: untrustedComment,
+		TrustedComment:   trustedComment,
+	}
+	if s.Signature, err = key.Sign(nil, m, crypto.Hash(0)); err != nil {
+		return nil, err
+	}
+	if s.GlobalSignature, err = key.Sign(nil, s.global(), crypto.Hash(0)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Signature) Verify(public *PublicKey, r io.Reader) error {
+	if s.KeyID != public.KeyID {
+		return ErrKeyID
+	}
+	m, err := message(s.Algorithm, r)
+	if err != nil {
+		return err
+	}
+	if err := public.Key.Verify(m, s.Signature); err != nil {
+		return err
+	}
+	return public.Key.Verify(s.global(), s.GlobalSignature)
+}
+
+func ParseSignature(data []byte) (*Signature, error) {
+	lines := splitLines(data)
+	if len(lines) < 4 ||
+		!strings.HasPrefix(lines[0], prefixUntrusted) ||
+		!strings.HasPrefix(lines[2], prefixTrusted) {
+		return nil, ErrFormat
+	}
+	b, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(b) != 2+KeyIDSize+sv.SignatureSize {
+		return nil, ErrFormat
+	}
+	g, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(g) != sv.SignatureSize {
+		return nil, ErrFormat
+	}
+	s := &Signature{
+		Algorithm:        string(b[:2]),
+		Signature:        b[2+KeyIDSize:],
+		UntrustedComment: strings.TrimPrefix(lines[0], prefixUntrusted),
+		TrustedComment:   strings.TrimPrefix(lines[2], prefixTrusted),
+		GlobalSignature:  g,
+	}
+	if s.Algorithm != AlgorithmPure && s.Algorithm != AlgorithmHashed {
+		return nil, ErrAlgorithm
+	}
+	copy(s.KeyID[:], b[2:])
+	return s, nil
+}
+
+func (s *Signature) MarshalText() ([]byte, error) {
+	if !validComment(s.TrustedComment) || !validComment(s.UntrustedComment) {
+		return nil, ErrComment
+	}
+	b := append([]byte(s.Algorithm), s.KeyID[:]...)
+	b = append(b, s.Signature...)
+	return fmt.Appendf(nil, "%s%s\n%s\n%s%s\n%s\n",
+		prefixUntrusted, s.UntrustedComment,
+		base64.StdEncoding.EncodeToString(b),
+		prefixTrusted, s.TrustedComment,
+		base64.StdEncoding.EncodeToString(s.GlobalSignature),
+	), nil
+}
+
+func IsSignature(data []byte) bool { return bytes.HasPrefix(data, []byte(prefixUntrusted)) }
+
+func (s *Signature) global() []byte {
+	return append(bytes.Clone(s.Signature), s.TrustedComment...)
+}
+
+func message(algorithm string, r io.Reader) ([]byte, error) {
+	switch algorithm {
+	case AlgorithmPure:
+		return io.ReadAll(r)
+	case AlgorithmHashed:
+		h, _ := blake2b.New512(nil)
+		if _, err := io.Copy(h, r); err != nil {
+			return nil, err
+		}
+		return h.Sum(nil), nil
+	}
+	return nil, ErrAlgorithm
+}
+
+func validComment(s string) bool {
+	return len(s) <= MaxCommentSize && !strings.ContainsAny(s, "\r\n")
+}
+
+func splitLines(data []byte) []string {
+	var lines []string
+	for line := range strings.Lines(string(data)) {
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,120 @@
+package gf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+)
+
+const (
+	Magic   = "\x00GEHEIM\x00"
+	Version = 1
+
+	KeySize  = 32
+	TypeKey  = "GEHEIM GIT KEY"
+	tagSize  = sha256.Size
+	ivSize   = aes.BlockSize
+	encLabel = "geheim/gf/enc"
+	macLabel = "geheim/gf/mac"
+)
+
+var (
+	ErrKey     = errors.New("gf: invalid key")
+	ErrFormat  = errors.New("gf: malformed ciphertext")
+	ErrVersion = errors.New("gf: unsupported version")
+	ErrMAC     = errors.New("gf: mac mismatch")
+)
+
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func MarshalKey(key []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: TypeKey, Bytes: key})
+}
+
+func ParseKey(data []byte) ([]byte, error) {
+	block, rest := pem.Decode(data)
+	if block == nil || block.Type != TypeKey || len(block.Bytes) != KeySize || len(bytes.TrimSpace(rest)) != 0 {
+		return nil, ErrKey
+	}
+	return block.Bytes, nil
+}
+
+func IsEncrypted(data []byte) bool { return bytes.HasPrefix(data, []byte(Magic)) }
+
+func deriveKeys(key []byte) (encKey, macKey []byte, err error) {
+	if len(key) != KeySize {
+		return nil, nil, ErrKey
+	}
+	if encKey, err = hkdf.Key(sha256.New, key, nil, encLabel, KeySize); err != nil {
+		return
+	}
+	macKey, err = hkdf.Key(sha256.New, key, nil, macLabel, KeySize)
+	return
+}
+
+func xor(encKey, iv, dst, src []byte) error {
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return err
+	}
+	cipher.NewCTR(block, iv).XORKeyStream(dst, src)
+	return nil
+}
+
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	encKey, macKey, err := deriveKeys(key)
+	if err != nil {
+		return nil, err
+	}
+	m := hmac.New(sha256.New, macKey)
+	m.Write([]byte{Version})
+	m.Write(plaintext)
+	tag := m.Sum(nil)
+	out := make([]byte, len(Magic)+1+tagSize+len(plaintext))
+	n := copy(out, Magic)
+	out[n] = Version
+	n += 1 + copy(out[n+1:], tag)
+	if err := xor(encKey, tag[:ivSize], out[n:], plaintext); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func Decrypt(key, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) || len(data) < len(Magic)+1+tagSize {
+		return nil, ErrFormat
+	}
+	data = data[len(Magic):]
+	if data[0] != Version {
+		return nil, ErrVersion
+	}
+	tag, ciphertext := data[1:1+tagSize], data[1+tagSize:]
+	encKey, macKey, err := deriveKeys(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	if err := xor(encKey, tag[:ivSize], plaintext, ciphertext); err != nil {
+		return nil, err
+	}
+	m := hmac.New(sha256.New, macKey)
+	m.Write([]byte{Version})
+	m.Write(plaintext)
+	if !hmac.Equal(m.Sum(nil), tag) {
+		clear(plaintext)
+		return nil, ErrMAC
+	}
+	return plaintext, nil
+}
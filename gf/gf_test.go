@@ -0,0 +1,185 @@
+package gf_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jamesliu96/geheim/gf"
+)
+
+func TestRoundTrip(t *testing.T) {
+	key, err := gf.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plaintext := range [][]byte{nil, {0}, []byte(gf.Magic), bytes.Repeat([]byte("plaintext"), 1000)} {
+		ciphertext, err := gf.Encrypt(key, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !gf.IsEncrypted(ciphertext) {
+			t.Fatal("missing magic")
+		}
+		again, err := gf.Encrypt(key, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ciphertext, again) {
+			t.Fatal("encryption is not deterministic")
+		}
+		got, err := gf.Decrypt(key, ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("plaintext %q, want %q", got, plaintext)
+		}
+	}
+	a, err := gf.Encrypt(key, []byte("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := gf.Encrypt(key, []byte("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a, b) {
+		t.Fatal("distinct plaintexts share a ciphertext")
+	}
+}
+
+func TestKey(t *testing.T) {
+	key, err := gf.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := gf.ParseKey(gf.MarshalKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed, key) {
+		t.Fatal("key mismatch")
+	}
+	for _, data := range [][]byte{nil, key, gf.MarshalKey(key[1:]), append(gf.MarshalKey(key), "trailing"...)} {
+		if _, err := gf.ParseKey(data); !errors.Is(err, gf.ErrKey) {
+			t.Fatalf("%v, want %v", err, gf.ErrKey)
+		}
+	}
+	if _, err := gf.Encrypt(key[1:], nil); !errors.Is(err, gf.ErrKey) {
+		t.Fatalf("%v, want %v", err, gf.ErrKey)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	key, err := gf.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := gf.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := gf.Encrypt(key, []byte("plaintext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gf.Decrypt(other, ciphertext); !errors.Is(err, gf.ErrMAC) {
+		t.Fatalf("%v, want %v", err, gf.ErrMAC)
+	}
+	for i := len(gf.Magic) + 1; i < len(ciphertext); i++ {
+		tampered := bytes.Clone(ciphertext)
+		tampered[i] ^= 1
+		if _, err := gf.Decrypt(key, tampered); !errors.Is(err, gf.ErrMAC) {
+			t.Fatalf("byte %d: %v, want %v", i, err, gf.ErrMAC)
+		}
+	}
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(gf.Magic)]++
+	if _, err := gf.Decrypt(key, tampered); !errors.Is(err, gf.ErrVersion) {
+		t.Fatalf("%v, want %v", err, gf.ErrVersion)
+	}
+	for _, data := range [][]byte{nil, []byte("plaintext"), ciphertext[:len(ciphertext)-len("plaintext")-1]} {
+		if _, err := gf.Decrypt(key, data); !errors.Is(err, gf.ErrFormat) {
+			t.Fatalf("%v, want %v", err, gf.ErrFormat)
+		}
+	}
+}
+
+func pkt(lines ...string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			b.WriteString("0000")
+			continue
+		}
+		fmt.Fprintf(&b, "%04x%s", len(line)+4, line)
+	}
+	return b.String()
+}
+
+func TestServe(t *testing.T) {
+	large := strings.Repeat("x", 100000)
+	var in strings.Builder
+	in.WriteString(pkt("git-filter-client\n", "version=2\n", ""))
+	in.WriteString(pkt("capability=clean\n", "capability=smudge\n", "capability=delay\n", ""))
+	in.WriteString(pkt("command=clean\n", "pathname=a.txt\n", "", "plain", "text", ""))
+	in.WriteString(pkt("command=smudge\n", "pathname=b.txt\n", "", "fail", ""))
+	in.WriteString(pkt("command=clean\n", "pathname=c.txt\n", ""))
+	for i := 0; i < len(large); i += 60000 {
+		in.WriteString(pkt(large[i:min(i+60000, len(large))]))
+	}
+	in.WriteString(pkt(""))
+	var out bytes.Buffer
+	var calls []string
+	err := gf.Serve(strings.NewReader(in.String()), &out, func(command, pathname string, data []byte) ([]byte, error) {
+		calls = append(calls, command+" "+pathname)
+		if string(data) == "fail" {
+			return nil, errors.New("fail")
+		}
+		return bytes.ToUpper(data), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"clean a.txt", "smudge b.txt", "clean c.txt"}; fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Fatalf("calls %q, want %q", calls, want)
+	}
+	var want strings.Builder
+	want.WriteString(pkt("git-filter-server\n", "version=2\n", ""))
+	want.WriteString(pkt("capability=clean\n", "capability=smudge\n", ""))
+	want.WriteString(pkt("status=success\n", "", "PLAINTEXT", "", ""))
+	want.WriteString(pkt("status=error\n", ""))
+	want.WriteString(pkt("status=success\n", ""))
+	upper := strings.ToUpper(large)
+	for i := 0; i < len(upper); i += 65516 {
+		want.WriteString(pkt(upper[i:min(i+65516, len(upper))]))
+	}
+	want.WriteString(pkt("", ""))
+	if out.String() != want.String() {
+		t.Fatal("response mismatch")
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	filter := func(command, pathname string, data []byte) ([]byte, error) { return data, nil }
+	for _, c := range []struct {
+		in  string
+		err error
+	}{
+		{pkt("git-filter-client\n", "version=1\n", ""), gf.ErrHandshake},
+		{pkt("git-filter-server\n", "version=2\n", ""), gf.ErrHandshake},
+		{"zzzz", gf.ErrPacket},
+		{"0004", gf.ErrPacket},
+		{"ffff", gf.ErrPacket},
+		{"0010abc", io.ErrUnexpectedEOF},
+		{pkt("git-filter-client\n", "version=2\n", "", "capability=clean\n", "", "command=clean\n", "", "data"), io.ErrUnexpectedEOF},
+	} {
+		if err := gf.Serve(strings.NewReader(c.in), io.Discard, filter); !errors.Is(err, c.err) {
+			t.Errorf("%q: %v, want %v", c.in, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package gf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	CommandClean  = "clean"
+	CommandSmudge = "smudge"
+
+	maxPacketSize = 65520
+	maxDataSize   = maxPacketSize - 4
+)
+
+var (
+	ErrPacket    = errors.New("gf: malformed packet")
+	ErrHandshake = errors.New("gf: invalid filter handshake")
+)
+
+type Filter func(command, pathname string, data []byte) ([]byte, error)
+
+type packetReader struct{ r io.Reader }
+
+func (p packetReader) read() (data []byte, flush bool, err error) {
+	var head [4]byte
+	if _, err = io.ReadFull(p.r, head[:]); err != nil {
+		return
+	}
+	n, err := strconv.ParseUint(string(head[:]), 16, 16)
+	if err != nil {
+		return nil, false, ErrPacket
+	}
+	switch {
+	case n == 0:
+		return nil, true, nil
+	case n <= 4 || n > maxPacketSize:
+		return nil, false, ErrPacket
+	}
+	data = make([]byte, n-4)
+	if _, err = io.ReadFull(p.r, data); err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return
+}
+
+func (p packetReader) lines() (lines []string, err error) {
+	for {
+		data, flush, err := p.read()
+		if err != nil || flush {
+			return lines, err
+		}
+		lines = append(lines, strings.TrimSuffix(string(data), "\n"))
+	}
+}
+
+func (p packetReader) content() ([]byte, error) {
+	var buf bytes.Buffer
+	for {
+		data, flush, err := p.read()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil || flush {
+			return buf.Bytes(), err
+		}
+		buf.Write(data)
+	}
+}
+
+type packetWriter struct{ w io.Writer }
+
+func (p packetWriter) flush() error {
+	_, err := io.WriteString(p.w, "0000")
+	return err
+}
+
+func (p packetWriter) write(data []byte) error {
+	if _, err := fmt.Fprintf(p.w, "%04x", len(data)+4); err != nil {
+		return err
+	}
+	_, err := p.w.Write(data)
+	return err
+}
+
+func (p packetWriter) lines(lines ...string) error {
+	for _, line := range lines {
+		if err := p.write([]byte(line + "\n")); err != nil {
+			return err
+		}
+	}
+	return p.flush()
+}
+
+func (p packetWriter) content(data []byte) error {
+	for len(data) > 0 {
+		n := min(len(data), maxDataSize)
+		if err := p.write(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return p.flush()
+}
+
+func handshake(pr packetReader, pw packetWriter) error {
+	lines, err := pr.lines()
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 || lines[0] != "git-filter-client" || !slices.Contains(lines[1:], "version=2") {
+		return ErrHandshake
+	}
+	if err := pw.lines("git-filter-server", "version=2"); err != nil {
+		return err
+	}
+	if lines, err = pr.lines(); err != nil {
+		return err
+	}
+	var capabilities []string
+	for _, c := range []string{CommandClean, CommandSmudge} {
+		if slices.Contains(lines, "capability="+c) {
+			capabilities = append(capabilities, "capability="+c)
+		}
+	}
+	return pw.lines(capabilities...)
+}
+
+func Serve(r io.Reader, w io.Writer, filter Filter) error {
+	pr, pw := packetReader{r}, packetWriter{w}
+	if err := handshake(pr, pw); err != nil {
+		return err
+	}
+	for {
+		lines, err := pr.lines()
+		if err == io.EOF && len(lines) == 0 {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var command, pathname string
+		for _, line := range lines {
+			k, v, _ := strings.Cut(line, "=")
+			switch k {
+			case "command":
+				command = v
+			case "pathname":
+				pathname = v
+			}
+		}
+		data, err := pr.content()
+		if err != nil {
+			return err
+		}
+		if data, err = filter(command, pathname, data); err != nil {
+			if err := pw.lines("status=error"); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := pw.lines("status=success"); err != nil {
+			return err
+		}
+		if err := pw.content(data); err != nil {
+			return err
+		}
+		if err := pw.flush(); err != nil {
+			return err
+		}
+	}
+}
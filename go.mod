@@ -6,4 +6,7 @@ require (
 	golang.org/x/crypto v0.54.0
 	golang.org/x/sys v0.47.0
 	golang.org/x/term v0.45.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require c2sp.org/CCTV/age v0.0.0-20251208015420-e9274a7bdbfd
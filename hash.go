@@ -75,3 +75,5 @@ func getHash(h Hash) (func() hash.Hash, error) {
 	}
 	return nil, ErrHash
 }
+
+func GetHash(h Hash) (func() hash.Hash, error) { return getHash(h) }
@@ -0,0 +1,105 @@
+package hd
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	Ed25519    = "ed25519 seed"
+	Curve25519 = "curve25519 seed"
+)
+
+const (
+	Hardened = 1 << 31
+
+	MinSeedSize = 16
+	MaxSeedSize = 64
+)
+
+var (
+	ErrSeed     = fmt.Errorf("hd: invalid seed size (%d-%d)", MinSeedSize, MaxSeedSize)
+	ErrPath     = errors.New("hd: malformed path")
+	ErrHardened = errors.New("hd: only hardened derivation is supported")
+)
+
+type Key struct {
+	Key, ChainCode []byte
+}
+
+func NewMaster(curve string, seed []byte) (Key, error) {
+	if len(seed) < MinSeedSize || len(seed) > MaxSeedSize {
+		return Key{}, ErrSeed
+	}
+	return split(hmacSHA512([]byte(curve), seed)), nil
+}
+
+func (k Key) Child(index uint32) (Key, error) {
+	if index < Hardened {
+		return Key{}, ErrHardened
+	}
+	data := make([]byte, 0, 1+len(k.Key)+4)
+	data = append(data, 0)
+	data = append(data, k.Key...)
+	data = binary.BigEndian.AppendUint32(data, index)
+	return split(hmacSHA512(k.ChainCode, data)), nil
+}
+
+func Derive(curve string, seed []byte, path string) (Key, error) {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return Key{}, err
+	}
+	k, err := NewMaster(curve, seed)
+	if err != nil {
+		return Key{}, err
+	}
+	for _, index := range indices {
+		if k, err = k.Child(index); err != nil {
+			return Key{}, err
+		}
+	}
+	return k, nil
+}
+
+func ParsePath(path string) ([]uint32, error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != "m" {
+		return nil, ErrPath
+	}
+	indices := make([]uint32, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		if segment == "" {
+			return nil, ErrPath
+		}
+		s, ok := strings.CutSuffix(segment, "'")
+		if !ok {
+			s, ok = strings.CutSuffix(segment, "H")
+		}
+		if !ok {
+			s, ok = strings.CutSuffix(segment, "h")
+		}
+		if !ok {
+			return nil, ErrHardened
+		}
+		index, err := strconv.ParseUint(s, 10, 31)
+		if err != nil {
+			return nil, ErrPath
+		}
+		indices = append(indices, uint32(index)+Hardened)
+	}
+	return indices, nil
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	h := hmac.New(sha512.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func split(i []byte) Key { return Key{i[:32], i[32:]} }
@@ -0,0 +1,191 @@
+package hd_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/jamesliu96/geheim/hd"
+	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xp"
+)
+
+type vector struct {
+	path, chainCode, key, public string
+}
+
+// https://github.com/satoshilabs/slips/blob/master/slip-0010.md test vector 1
+const seed1 = "000102030405060708090a0b0c0d0e0f"
+
+var ed25519Vectors = []vector{
+	{
+		"m",
+		"90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb",
+		"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		"00a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+	},
+	{
+		"m/0H",
+		"8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69",
+		"68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+		"008c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+	},
+	{
+		"m/0H/1H",
+		"a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14",
+		"b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+		"001932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187",
+	},
+	{
+		"m/0H/1H/2H",
+		"2e69929e00b5ab250f49c3fb1c12f252de4fed2c1db88387094a0f8c4c9ccd6c",
+		"92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+		"00ae98736566d30ed0e9d2f4486a64bc95740d89c7db33f52121f8ea8f76ff0fc1",
+	},
+	{
+		"m/0H/1H/2H/2H",
+		"8f6d87f93d750e0efccda017d662a1b31a266e4a6f5993b15f5c1f07f74dd5cc",
+		"30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662",
+		"008abae2d66361c879b900d204ad2cc4984fa2aa344dd7ddc46007329ac76c429c",
+	},
+	{
+		"m/0H/1H/2H/2H/1000000000H",
+		"68789923a0cac2cd5a29172a475fe9e0fb14cd6adb5ad98a3fa70333e7afa230",
+		"8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+		"003c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a",
+	},
+}
+
+var curve25519Vectors = []vector{
+	{
+		"m",
+		"77997ca3588a1a34f3589279ea2962247abfe5277d52770a44c706378c710768",
+		"d70a59c2e68b836cc4bbe8bcae425169b9e2384f3905091e3d60b890e90cd92c",
+		"005c7289dc9f7f3ea1c8c2de7323b9fb0781f69c9ecd6de4f095ac89a02dc80577",
+	},
+	{
+		"m/0H",
+		"349a3973aad771c628bf1f1b4d5e071f18eff2e492e4aa7972a7e43895d6597f",
+		"cd7630d7513cbe80515f7317cdb9a47ad4a56b63c3f1dc29583ab8d4cc25a9b2",
+		"00cb8be6b256ce509008b43ae0dccd69960ad4f7ff2e2868c1fbc9e19ec3ad544b",
+	},
+}
+
+func decode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func testVectors(t *testing.T, curve string, vectors []vector, public func(seed []byte, path string) ([]byte, error)) {
+	seed := decode(t, seed1)
+	for _, v := range vectors {
+		k, err := hd.Derive(curve, seed, v.path)
+		if err != nil {
+			t.Fatalf("%s: %v", v.path, err)
+		}
+		if !bytes.Equal(k.ChainCode, decode(t, v.chainCode)) {
+			t.Errorf("%s: chain code %x, want %s", v.path, k.ChainCode, v.chainCode)
+		}
+		if !bytes.Equal(k.Key, decode(t, v.key)) {
+			t.Errorf("%s: key %x, want %s", v.path, k.Key, v.key)
+		}
+		pub, err := public(seed, v.path)
+		if err != nil {
+			t.Fatalf("%s: %v", v.path, err)
+		}
+		if pub = append([]byte{0}, pub...); !bytes.Equal(pub, decode(t, v.public)) {
+			t.Errorf("%s: public %x, want %s", v.path, pub, v.public)
+		}
+	}
+}
+
+func TestEd25519(t *testing.T) {
+	testVectors(t, hd.Ed25519, ed25519Vectors, func(seed []byte, path string) ([]byte, error) {
+		k, err := sv.Derive(seed, path)
+		if err != nil {
+			return nil, err
+		}
+		return k.PublicKey().Bytes(), nil
+	})
+}
+
+func TestCurve25519(t *testing.T) {
+	testVectors(t, hd.Curve25519, curve25519Vectors, func(seed []byte, path string) ([]byte, error) {
+		k, err := xp.Derive(seed, path)
+		if err != nil {
+			return nil, err
+		}
+		return k.PublicKey().Bytes(), nil
+	})
+}
+
+func TestParsePath(t *testing.T) {
+	for path, want := range map[string][]uint32{
+		"m":             {},
+		"m/0'":          {hd.Hardened},
+		"m/0h/1H/2'":    {hd.Hardened, hd.Hardened + 1, hd.Hardened + 2},
+		"m/2147483647H": {hd.Hardened + 2147483647},
+	} {
+		indices, err := hd.ParsePath(path)
+		if err != nil {
+			t.Fatalf("%q: %v", path, err)
+		}
+		if len(indices) != len(want) {
+			t.Fatalf("%q: %v, want %v", path, indices, want)
+		}
+		for i := range want {
+			if indices[i] != want[i] {
+				t.Fatalf("%q: %v, want %v", path, indices, want)
+			}
+		}
+	}
+	for path, want := range map[string]error{
+		"":              hd.ErrPath,
+		"M/0H":          hd.ErrPath,
+		"0H":            hd.ErrPath,
+		"m/":            hd.ErrPath,
+		"m//0H":         hd.ErrPath,
+		"m/H":           hd.ErrPath,
+		"m/xH":          hd.ErrPath,
+		"m/-1H":         hd.ErrPath,
+		"m/+1H":         hd.ErrPath,
+		"m/2147483648H": hd.ErrPath,
+		"m/0":           hd.ErrHardened,
+		"m/0H/1":        hd.ErrHardened,
+	} {
+		if _, err := hd.ParsePath(path); !errors.Is(err, want) {
+			t.Errorf("%q: %v, want %v", path, err, want)
+		}
+	}
+}
+
+func TestHardened(t *testing.T) {
+	k, err := hd.NewMaster(hd.Ed25519, decode(t, seed1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range []uint32{0, 1, hd.Hardened - 1} {
+		if _, err := k.Child(index); !errors.Is(err, hd.ErrHardened) {
+			t.Errorf("%d: %v, want %v", index, err, hd.ErrHardened)
+		}
+	}
+	if _, err := k.Child(hd.Hardened); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hd.Derive(hd.Ed25519, decode(t, seed1), "m/0H/1"); !errors.Is(err, hd.ErrHardened) {
+		t.Fatalf("%v, want %v", err, hd.ErrHardened)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	for _, size := range []int{0, hd.MinSeedSize - 1, hd.MaxSeedSize + 1} {
+		if _, err := hd.NewMaster(hd.Ed25519, make([]byte, size)); !errors.Is(err, hd.ErrSeed) {
+			t.Errorf("%d: %v, want %v", size, err, hd.ErrSeed)
+		}
+	}
+}
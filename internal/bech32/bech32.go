@@ -0,0 +1,120 @@
+package bech32
+
+import (
+	"errors"
+	"strings"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+type Spec uint32
+
+const (
+	Bech32  Spec = 1
+	Bech32m Spec = 0x2bc830a3
+)
+
+var ErrFormat = errors.New("bech32: malformed string")
+
+func polymod(values []byte) uint32 {
+	gen := [...]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := range 5 {
+			if (b>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	d := make([]byte, 0, len(hrp)*2+1)
+	for i := range len(hrp) {
+		d = append(d, hrp[i]>>5)
+	}
+	d = append(d, 0)
+	for i := range len(hrp) {
+		d = append(d, hrp[i]&31)
+	}
+	return d
+}
+
+func convertBits(data []byte, from, to uint, pad bool) ([]byte, error) {
+	var (
+		acc  uint32
+		bits uint
+		d    []byte
+	)
+	maxv := uint32(1)<<to - 1
+	for _, v := range data {
+		if uint32(v)>>from != 0 {
+			return nil, ErrFormat
+		}
+		acc = acc<<from | uint32(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			d = append(d, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			d = append(d, byte(acc<<(to-bits)&maxv))
+		}
+	} else if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil, ErrFormat
+	}
+	return d, nil
+}
+
+func Encode(spec Spec, hrp string, data []byte) (string, error) {
+	values, err := convertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	checksum := polymod(append(append(hrpExpand(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ uint32(spec)
+	var b strings.Builder
+	b.WriteString(hrp)
+	b.WriteByte('1')
+	for _, v := range values {
+		b.WriteByte(charset[v])
+	}
+	for i := range 6 {
+		b.WriteByte(charset[checksum>>(5*(5-i))&31])
+	}
+	return b.String(), nil
+}
+
+func Decode(spec Spec, s string) (hrp string, data []byte, err error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, ErrFormat
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, ErrFormat
+	}
+	hrp = s[:pos]
+	for i := range len(hrp) {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, ErrFormat
+		}
+	}
+	values := make([]byte, 0, len(s)-pos-1)
+	for i := pos + 1; i < len(s); i++ {
+		v := strings.IndexByte(charset, s[i])
+		if v < 0 {
+			return "", nil, ErrFormat
+		}
+		values = append(values, byte(v))
+	}
+	if polymod(append(hrpExpand(hrp), values...)) != uint32(spec) {
+		return "", nil, ErrFormat
+	}
+	data, err = convertBits(values[:len(values)-6], 5, 8, false)
+	return
+}
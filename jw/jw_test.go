@@ -0,0 +1,131 @@
+package jw_test
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jamesliu96/geheim/jw"
+	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xp"
+)
+
+// RFC 8037 appendix A
+const (
+	rfcJWK        = `{"kty":"OKP","crv":"Ed25519","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	rfcThumbprint = "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"
+	rfcPayload    = "Example of Ed25519 signing"
+	rfcJWS        = "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc.hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+)
+
+func TestRFC8037(t *testing.T) {
+	jwk, err := jw.ParseJWK([]byte(rfcJWK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jwk.Thumbprint() != rfcThumbprint {
+		t.Fatalf("thumbprint %s, want %s", jwk.Thumbprint(), rfcThumbprint)
+	}
+	private, err := jwk.PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := private.(*sv.PrivateKey)
+	parts := strings.Split(rfcJWS, ".")
+	signature, err := key.Sign(nil, []byte(parts[0]+"."+parts[1]), crypto.Hash(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(signature); got != parts[2] {
+		t.Fatalf("signature %s, want %s", got, parts[2])
+	}
+	payload, header, err := jw.Verify([]byte(rfcJWS), key.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != rfcPayload || header["alg"] != jw.AlgEdDSA {
+		t.Fatalf("payload %q, header %v", payload, header)
+	}
+	signature[0] ^= 1
+	tampered := parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature)
+	if _, _, err := jw.Verify([]byte(tampered), key.PublicKey()); !errors.Is(err, jw.ErrSignature) {
+		t.Fatalf("%v, want %v", err, jw.ErrSignature)
+	}
+}
+
+func TestJWS(t *testing.T) {
+	a, err := sv.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := sv.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("payload")
+	token, err := jw.Sign(a, payload, jw.Header{"typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, header, err := jw.Verify([]byte(token), a.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) || header["typ"] != "JWT" {
+		t.Fatalf("payload %q, header %v", got, header)
+	}
+	if _, _, err := jw.Verify([]byte(token), b.PublicKey()); !errors.Is(err, jw.ErrSignature) {
+		t.Fatalf("%v, want %v", err, jw.ErrSignature)
+	}
+	data, err := jw.SignJSON([]*sv.PrivateKey{a, b}, payload, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []*sv.PrivateKey{a, b} {
+		if got, _, err := jw.Verify(data, key.PublicKey()); err != nil || !bytes.Equal(got, payload) {
+			t.Fatalf("payload %q: %v", got, err)
+		}
+	}
+}
+
+func TestJWE(t *testing.T) {
+	key, err := xp.GenerateKey(xp.X25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := xp.GenerateKey(xp.X25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("plaintext")
+	token, err := jw.Encrypt(key.PublicKey(), plaintext, jw.Header{"cty": "text/plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, header, err := jw.Decrypt(token, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) || header["alg"] != jw.AlgECDHESA256KW || header["enc"] != jw.EncA256GCM || header["cty"] != "text/plain" {
+		t.Fatalf("plaintext %q, header %v", got, header)
+	}
+	if _, _, err := jw.Decrypt(token, other); !errors.Is(err, jw.ErrUnwrap) {
+		t.Fatalf("%v, want %v", err, jw.ErrUnwrap)
+	}
+	parts := strings.Split(token, ".")
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext[0] ^= 1
+	parts[3] = base64.RawURLEncoding.EncodeToString(ciphertext)
+	if _, _, err := jw.Decrypt(strings.Join(parts, "."), key); !errors.Is(err, jw.ErrDecrypt) {
+		t.Fatalf("%v, want %v", err, jw.ErrDecrypt)
+	}
+	if _, _, err := jw.Decrypt(strings.Join(parts[:4], "."), key); !errors.Is(err, jw.ErrJWE) {
+		t.Fatalf("%v, want %v", err, jw.ErrJWE)
+	}
+}
@@ -0,0 +1,248 @@
+package jw
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/jamesliu96/geheim/xp"
+)
+
+const (
+	AlgECDHESA256KW = "ECDH-ES+A256KW"
+	EncA256GCM      = "A256GCM"
+)
+
+const keySize = 32
+
+var (
+	ErrJWE     = errors.New("jw: malformed jwe")
+	ErrUnwrap  = errors.New("jw: key unwrap failed")
+	ErrDecrypt = errors.New("jw: decryption failed")
+)
+
+func Encrypt(recipient *xp.PublicKey, plaintext []byte, header Header) (string, error) {
+	if recipient.Curve() != xp.X25519 {
+		return "", ErrKey
+	}
+	ephemeral, err := xp.GenerateKey(xp.X25519)
+	if err != nil {
+		return "", err
+	}
+	epk, err := NewJWK(ephemeral.PublicKey())
+	if err != nil {
+		return "", err
+	}
+	h := Header{}
+	for k, v := range header {
+		h[k] = v
+	}
+	h["alg"] = AlgECDHESA256KW
+	h["enc"] = EncA256GCM
+	h["epk"] = epk.Public()
+	if _, ok := h["kid"]; !ok {
+		jwk, err := NewJWK(recipient)
+		if err != nil {
+			return "", err
+		}
+		h["kid"] = jwk.KeyID
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return "", err
+	}
+	kek, err := concatKDF(shared, h)
+	if err != nil {
+		return "", err
+	}
+	cek := make([]byte, keySize)
+	if _, err := rand.Read(cek); err != nil {
+		return "", err
+	}
+	wrapped, err := keyWrap(kek, cek)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+	protected := b64.EncodeToString(data)
+	aead, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(plaintext)], sealed[len(plaintext):]
+	return strings.Join([]string{protected, b64.EncodeToString(wrapped), b64.EncodeToString(iv), b64.EncodeToString(ciphertext), b64.EncodeToString(tag)}, "."), nil
+}
+
+func Decrypt(token string, key *xp.PrivateKey) (plaintext []byte, header Header, err error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 5 {
+		err = ErrJWE
+		return
+	}
+	if header, err = decodeHeader(parts[0]); err != nil {
+		return
+	}
+	if header["alg"] != AlgECDHESA256KW || header["enc"] != EncA256GCM {
+		err = ErrAlgorithm
+		return
+	}
+	if _, ok := header["crit"]; ok {
+		err = ErrCritical
+		return
+	}
+	if _, ok := header["zip"]; ok {
+		err = ErrCritical
+		return
+	}
+	data, err := json.Marshal(header["epk"])
+	if err != nil {
+		return
+	}
+	epk, err := ParseJWK(data)
+	if err != nil {
+		return
+	}
+	public, err := epk.PublicKey()
+	if err != nil {
+		return
+	}
+	ephemeral, ok := public.(*xp.PublicKey)
+	if !ok || epk.D != "" {
+		err = ErrKey
+		return
+	}
+	shared, err := key.ECDH(ephemeral)
+	if err != nil {
+		return
+	}
+	kek, err := concatKDF(shared, header)
+	if err != nil {
+		return
+	}
+	var wrapped, iv, ciphertext, tag []byte
+	for i, b := range []*[]byte{&wrapped, &iv, &ciphertext, &tag} {
+		if *b, err = b64.DecodeString(parts[i+1]); err != nil {
+			return
+		}
+	}
+	cek, err := keyUnwrap(kek, wrapped)
+	if err != nil {
+		return
+	}
+	aead, err := newGCM(cek)
+	if err != nil {
+		return
+	}
+	if len(iv) != aead.NonceSize() || len(tag) != aead.Overhead() {
+		err = ErrJWE
+		return
+	}
+	if plaintext, err = aead.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0])); err != nil {
+		err = ErrDecrypt
+	}
+	return
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, ErrUnwrap
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func concatKDF(shared []byte, header Header) ([]byte, error) {
+	var info []byte
+	for _, name := range []string{"alg", "apu", "apv"} {
+		var value []byte
+		switch name {
+		case "alg":
+			value = []byte(AlgECDHESA256KW)
+		default:
+			if s, ok := header[name].(string); ok {
+				var err error
+				if value, err = b64.DecodeString(s); err != nil {
+					return nil, err
+				}
+			} else if header[name] != nil {
+				return nil, ErrJWE
+			}
+		}
+		info = binary.BigEndian.AppendUint32(info, uint32(len(value)))
+		info = append(info, value...)
+	}
+	info = binary.BigEndian.AppendUint32(info, keySize*8)
+	h := sha256.New()
+	h.Write([]byte{0, 0, 0, 1})
+	h.Write(shared)
+	h.Write(info)
+	return h.Sum(nil), nil
+}
+
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+func keyWrap(kek, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(key) / 8
+	r := make([]byte, 8+len(key))
+	copy(r, keyWrapIV)
+	copy(r[8:], key)
+	b := make([]byte, 16)
+	for j := range 6 {
+		for i := 1; i <= n; i++ {
+			copy(b, r[:8])
+			copy(b[8:], r[i*8:i*8+8])
+			block.Encrypt(b, b)
+			binary.BigEndian.PutUint64(r[:8], binary.BigEndian.Uint64(b[:8])^uint64(n*j+i))
+			copy(r[i*8:], b[8:])
+		}
+	}
+	return r, nil
+}
+
+func keyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, ErrUnwrap
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	r := make([]byte, len(wrapped))
+	copy(r, wrapped)
+	b := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(r[:8])^uint64(n*j+i))
+			copy(b[8:], r[i*8:i*8+8])
+			block.Decrypt(b, b)
+			copy(r[:8], b[:8])
+			copy(r[i*8:], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(r[:8], keyWrapIV) != 1 {
+		return nil, ErrUnwrap
+	}
+	return r[8:], nil
+}
@@ -0,0 +1,137 @@
+package jw
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xp"
+)
+
+const (
+	KeyTypeOKP = "OKP"
+
+	CurveEd25519 = "Ed25519"
+	CurveX25519  = "X25519"
+)
+
+var (
+	ErrJWK = errors.New("jw: invalid jwk")
+	ErrKey = errors.New("jw: unsupported key")
+)
+
+var b64 = base64.RawURLEncoding
+
+type JWK struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	D       string `json:"d,omitempty"`
+	KeyID   string `json:"kid,omitempty"`
+	Use     string `json:"use,omitempty"`
+	Alg     string `json:"alg,omitempty"`
+}
+
+func NewJWK(key any) (*JWK, error) {
+	var (
+		crv     string
+		public  []byte
+		private []byte
+	)
+	switch k := key.(type) {
+	case *sv.PrivateKey:
+		crv, public, private = CurveEd25519, k.PublicKey().Bytes(), k.Seed()
+	case *sv.PublicKey:
+		crv, public = CurveEd25519, k.Bytes()
+	case *xp.PrivateKey:
+		if k.Curve() != xp.X25519 {
+			return nil, ErrKey
+		}
+		crv, public, private = CurveX25519, k.PublicKey().Bytes(), k.Bytes()
+	case *xp.PublicKey:
+		if k.Curve() != xp.X25519 {
+			return nil, ErrKey
+		}
+		crv, public = CurveX25519, k.Bytes()
+	default:
+		return nil, ErrKey
+	}
+	jwk := &JWK{KeyType: KeyTypeOKP, Curve: crv, X: b64.EncodeToString(public)}
+	if private != nil {
+		jwk.D = b64.EncodeToString(private)
+	}
+	jwk.KeyID = jwk.Thumbprint()
+	return jwk, nil
+}
+
+func ParseJWK(data []byte) (*JWK, error) {
+	jwk := new(JWK)
+	if err := json.Unmarshal(data, jwk); err != nil {
+		return nil, err
+	}
+	if jwk.KeyType != KeyTypeOKP || (jwk.Curve != CurveEd25519 && jwk.Curve != CurveX25519) {
+		return nil, ErrKey
+	}
+	if _, err := jwk.PublicKey(); err != nil {
+		return nil, err
+	}
+	if jwk.D != "" {
+		if _, err := jwk.PrivateKey(); err != nil {
+			return nil, err
+		}
+	}
+	return jwk, nil
+}
+
+func (k *JWK) Thumbprint() string {
+	h := sha256.Sum256(fmt.Appendf(nil, `{"crv":%q,"kty":%q,"x":%q}`, k.Curve, k.KeyType, k.X))
+	return b64.EncodeToString(h[:])
+}
+
+func (k *JWK) Public() *JWK {
+	return &JWK{KeyType: k.KeyType, Curve: k.Curve, X: k.X, KeyID: k.KeyID, Use: k.Use, Alg: k.Alg}
+}
+
+func (k *JWK) PublicKey() (kf.PublicKey, error) {
+	x, err := b64.DecodeString(k.X)
+	if err != nil {
+		return nil, ErrJWK
+	}
+	switch k.Curve {
+	case CurveEd25519:
+		return sv.NewPublicKey(x)
+	case CurveX25519:
+		return xp.NewPublicKey(xp.X25519, x)
+	}
+	return nil, ErrKey
+}
+
+func (k *JWK) PrivateKey() (key kf.PrivateKey, err error) {
+	d, err := b64.DecodeString(k.D)
+	if err != nil || len(d) == 0 {
+		return nil, ErrJWK
+	}
+	switch k.Curve {
+	case CurveEd25519:
+		key, err = sv.NewKeyFromSeed(d)
+	case CurveX25519:
+		key, err = xp.NewPrivateKey(xp.X25519, d)
+	default:
+		err = ErrKey
+	}
+	if err != nil {
+		return
+	}
+	public, err := kf.Public(key)
+	if err != nil {
+		return
+	}
+	if b64.EncodeToString(public.Bytes()) != k.X {
+		return nil, ErrJWK
+	}
+	return
+}
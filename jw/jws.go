@@ -0,0 +1,164 @@
+package jw
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/jamesliu96/geheim/sv"
+)
+
+const AlgEdDSA = "EdDSA"
+
+var (
+	ErrJWS       = errors.New("jw: malformed jws")
+	ErrAlgorithm = errors.New("jw: unsupported algorithm")
+	ErrCritical  = errors.New("jw: unsupported critical header")
+	ErrSignature = errors.New("jw: no valid signature")
+)
+
+type Header map[string]any
+
+type jsonSignature struct {
+	Protected string `json:"protected"`
+	Header    Header `json:"header,omitempty"`
+	Signature string `json:"signature"`
+}
+
+type jsonJWS struct {
+	Payload    string          `json:"payload"`
+	Protected  string          `json:"protected,omitempty"`
+	Header     Header          `json:"header,omitempty"`
+	Signature  string          `json:"signature,omitempty"`
+	Signatures []jsonSignature `json:"signatures,omitempty"`
+}
+
+func signingHeader(key *sv.PrivateKey, header Header) (string, error) {
+	h := Header{}
+	for k, v := range header {
+		h[k] = v
+	}
+	h["alg"] = AlgEdDSA
+	if _, ok := h["kid"]; !ok {
+		jwk, err := NewJWK(key.PublicKey())
+		if err != nil {
+			return "", err
+		}
+		h["kid"] = jwk.KeyID
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+	return b64.EncodeToString(data), nil
+}
+
+func sign(key *sv.PrivateKey, protected, payload string) (string, error) {
+	signature, err := key.Sign(nil, []byte(protected+"."+payload), crypto.Hash(0))
+	if err != nil {
+		return "", err
+	}
+	return b64.EncodeToString(signature), nil
+}
+
+func Sign(key *sv.PrivateKey, payload []byte, header Header) (string, error) {
+	protected, err := signingHeader(key, header)
+	if err != nil {
+		return "", err
+	}
+	p := b64.EncodeToString(payload)
+	signature, err := sign(key, protected, p)
+	if err != nil {
+		return "", err
+	}
+	return protected + "." + p + "." + signature, nil
+}
+
+func SignJSON(keys []*sv.PrivateKey, payload []byte, header Header) ([]byte, error) {
+	jws := jsonJWS{Payload: b64.EncodeToString(payload)}
+	for _, key := range keys {
+		protected, err := signingHeader(key, header)
+		if err != nil {
+			return nil, err
+		}
+		signature, err := sign(key, protected, jws.Payload)
+		if err != nil {
+			return nil, err
+		}
+		jws.Signatures = append(jws.Signatures, jsonSignature{Protected: protected, Signature: signature})
+	}
+	if len(jws.Signatures) == 0 {
+		return nil, ErrSignature
+	}
+	return json.Marshal(jws)
+}
+
+func IsJSON(data []byte) bool { return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) }
+
+func Verify(data []byte, key *sv.PublicKey) (payload []byte, header Header, err error) {
+	var jws jsonJWS
+	if IsJSON(data) {
+		if err = json.Unmarshal(data, &jws); err != nil {
+			return
+		}
+		if len(jws.Signatures) == 0 {
+			jws.Signatures = []jsonSignature{{jws.Protected, jws.Header, jws.Signature}}
+		}
+	} else {
+		parts := strings.Split(strings.TrimSpace(string(data)), ".")
+		if len(parts) != 3 {
+			err = ErrJWS
+			return
+		}
+		jws.Payload = parts[1]
+		jws.Signatures = []jsonSignature{{Protected: parts[0], Signature: parts[2]}}
+	}
+	for _, s := range jws.Signatures {
+		if header, err = verify(s, jws.Payload, key); err == nil {
+			payload, err = b64.DecodeString(jws.Payload)
+			return
+		}
+	}
+	return nil, nil, ErrSignature
+}
+
+func verify(s jsonSignature, payload string, key *sv.PublicKey) (header Header, err error) {
+	if header, err = decodeHeader(s.Protected); err != nil {
+		return
+	}
+	for k, v := range s.Header {
+		if _, ok := header[k]; ok {
+			err = ErrJWS
+			return
+		}
+		header[k] = v
+	}
+	if header["alg"] != AlgEdDSA {
+		err = ErrAlgorithm
+		return
+	}
+	if _, ok := header["crit"]; ok {
+		err = ErrCritical
+		return
+	}
+	signature, err := b64.DecodeString(s.Signature)
+	if err != nil {
+		return
+	}
+	err = key.Verify([]byte(s.Protected+"."+payload), signature)
+	return
+}
+
+func decodeHeader(s string) (header Header, err error) {
+	data, err := b64.DecodeString(s)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &header)
+	if err == nil && header == nil {
+		err = ErrJWS
+	}
+	return
+}
@@ -0,0 +1,278 @@
+package kc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jamesliu96/geheim/sv"
+)
+
+type Usage uint8
+
+const (
+	UsageSign Usage = 1 << iota
+	UsageCertify
+)
+
+var UsageNames = map[Usage]string{
+	UsageSign:    "sign",
+	UsageCertify: "certify",
+}
+
+var usages = [...]Usage{
+	UsageSign,
+	UsageCertify,
+}
+
+var UsageString = func() string {
+	d := make([]string, len(usages))
+	for i, u := range usages {
+		d[i] = fmt.Sprintf("%d:%s", u, UsageNames[u])
+	}
+	return strings.Join(d, ", ")
+}()
+
+func (u Usage) String() string {
+	var d []string
+	for _, uu := range usages {
+		if u&uu != 0 {
+			d = append(d, UsageNames[uu])
+		}
+	}
+	return strings.Join(d, ",")
+}
+
+const (
+	TypeCertificate = "GEHEIM KEY CERTIFICATE"
+	TypeRevocation  = "GEHEIM KEY REVOCATION"
+)
+
+const (
+	version = 1
+
+	contextCertificate = "geheim/kc/certificate"
+	contextRevocation  = "geheim/kc/revocation"
+)
+
+var (
+	ErrFormat   = errors.New("kc: malformed statement")
+	ErrVersion  = errors.New("kc: unsupported version")
+	ErrName     = errors.New("kc: invalid name")
+	ErrUsage    = fmt.Errorf("kc: invalid usage (%s)", UsageString)
+	ErrValidity = errors.New("kc: invalid validity period")
+	ErrPEM      = errors.New("kc: unexpected pem block")
+)
+
+type Certificate struct {
+	Subject   *sv.PublicKey
+	Issuer    *sv.PublicKey
+	Name      string
+	NotBefore time.Time
+	NotAfter  time.Time
+	Usage     Usage
+	Signature []byte
+}
+
+type Revocation struct {
+	Key       *sv.PublicKey
+	Issuer    *sv.PublicKey
+	Time      time.Time
+	Reason    string
+	Signature []byte
+}
+
+func Certify(issuer *sv.PrivateKey, subject *sv.PublicKey, name string, notBefore, notAfter time.Time, usage Usage) (*Certificate, error) {
+	if len(name) > math.MaxUint16 {
+		return nil, ErrName
+	}
+	if usage == 0 || usage&^(UsageSign|UsageCertify) != 0 {
+		return nil, ErrUsage
+	}
+	if !notAfter.After(notBefore) {
+		return nil, ErrValidity
+	}
+	c := &Certificate{
+		Subject:   subject,
+		Issuer:    issuer.PublicKey(),
+		Name:      name,
+		NotBefore: notBefore.Truncate(time.Second),
+		NotAfter:  notAfter.Truncate(time.Second),
+		Usage:     usage,
+	}
+	var err error
+	if c.Signature, err = issuer.SignMode(sv.Context, bytes.NewReader(c.tbs()), contextCertificate); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func Revoke(issuer *sv.PrivateKey, key *sv.PublicKey, t time.Time, reason string) (*Revocation, error) {
+	if len(reason) > math.MaxUint16 {
+		return nil, ErrName
+	}
+	r := &Revocation{
+		Key:    key,
+		Issuer: issuer.PublicKey(),
+		Time:   t.Truncate(time.Second),
+		Reason: reason,
+	}
+	var err error
+	if r.Signature, err = issuer.SignMode(sv.Context, bytes.NewReader(r.tbs()), contextRevocation); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (c *Certificate) CheckSignature() error {
+	return c.Issuer.VerifyMode(sv.Context, bytes.NewReader(c.tbs()), c.Signature, contextCertificate)
+}
+
+func (c *Certificate) Valid(t time.Time) bool { return !t.Before(c.NotBefore) && !t.After(c.NotAfter) }
+
+func (r *Revocation) CheckSignature() error {
+	return r.Issuer.VerifyMode(sv.Context, bytes.NewReader(r.tbs()), r.Signature, contextRevocation)
+}
+
+func (c *Certificate) tbs() []byte {
+	b := []byte{version, byte(c.Usage)}
+	b = binary.BigEndian.AppendUint64(b, uint64(c.NotBefore.Unix()))
+	b = binary.BigEndian.AppendUint64(b, uint64(c.NotAfter.Unix()))
+	b = append(b, c.Subject.Bytes()...)
+	b = append(b, c.Issuer.Bytes()...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.Name)))
+	return append(b, c.Name...)
+}
+
+func (r *Revocation) tbs() []byte {
+	b := []byte{version}
+	b = binary.BigEndian.AppendUint64(b, uint64(r.Time.Unix()))
+	b = append(b, r.Key.Bytes()...)
+	b = append(b, r.Issuer.Bytes()...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(r.Reason)))
+	return append(b, r.Reason...)
+}
+
+func (c *Certificate) MarshalBinary() ([]byte, error) { return append(c.tbs(), c.Signature...), nil }
+
+func (r *Revocation) MarshalBinary() ([]byte, error) { return append(r.tbs(), r.Signature...), nil }
+
+func (c *Certificate) UnmarshalBinary(data []byte) (err error) {
+	d := decoder{data: data}
+	if d.byte() != version && d.err == nil {
+		return ErrVersion
+	}
+	c.Usage = Usage(d.byte())
+	c.NotBefore = d.time()
+	c.NotAfter = d.time()
+	c.Subject = d.key()
+	c.Issuer = d.key()
+	c.Name = d.string()
+	c.Signature = d.signature()
+	return d.err
+}
+
+func (r *Revocation) UnmarshalBinary(data []byte) (err error) {
+	d := decoder{data: data}
+	if d.byte() != version && d.err == nil {
+		return ErrVersion
+	}
+	r.Time = d.time()
+	r.Key = d.key()
+	r.Issuer = d.key()
+	r.Reason = d.string()
+	r.Signature = d.signature()
+	return d.err
+}
+
+func (c *Certificate) MarshalPEM() ([]byte, error) {
+	b, err := c.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: TypeCertificate, Bytes: b}), nil
+}
+
+func (r *Revocation) MarshalPEM() ([]byte, error) {
+	b, err := r.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: TypeRevocation, Bytes: b}), nil
+}
+
+func Parse(data []byte) (certificates []*Certificate, revocations []*Revocation, err error) {
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+		switch block.Type {
+		case TypeCertificate:
+			c := new(Certificate)
+			if err = c.UnmarshalBinary(block.Bytes); err != nil {
+				return
+			}
+			certificates = append(certificates, c)
+		case TypeRevocation:
+			r := new(Revocation)
+			if err = r.UnmarshalBinary(block.Bytes); err != nil {
+				return
+			}
+			revocations = append(revocations, r)
+		default:
+			err = ErrPEM
+			return
+		}
+	}
+	if len(bytes.TrimSpace(data)) != 0 {
+		err = ErrFormat
+	}
+	return
+}
+
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil || len(d.data) < n {
+		d.err = ErrFormat
+		return make([]byte, n)
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) byte() byte { return d.next(1)[0] }
+
+func (d *decoder) time() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint64(d.next(8))), 0)
+}
+
+func (d *decoder) key() *sv.PublicKey {
+	k, err := sv.NewPublicKey(d.next(sv.PublicSize))
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+	return k
+}
+
+func (d *decoder) string() string {
+	return string(d.next(int(binary.BigEndian.Uint16(d.next(2)))))
+}
+
+func (d *decoder) signature() []byte {
+	b := bytes.Clone(d.next(sv.SignatureSize))
+	if d.err == nil && len(d.data) != 0 {
+		d.err = ErrFormat
+	}
+	return b
+}
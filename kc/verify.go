@@ -0,0 +1,94 @@
+package kc
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jamesliu96/geheim/sv"
+)
+
+const MaxDepth = 8
+
+var (
+	ErrUntrusted = errors.New("kc: no trusted chain")
+	ErrRevoked   = errors.New("kc: key revoked")
+)
+
+type Verifier struct {
+	Roots        []*sv.PublicKey
+	Certificates []*Certificate
+	Revocations  []*Revocation
+}
+
+func (v *Verifier) Verify(key *sv.PublicKey, usage Usage, t time.Time) ([]*Certificate, error) {
+	return v.verify(key, usage, t, 0)
+}
+
+func (v *Verifier) VerifySignature(key *sv.PublicKey, message, signature []byte, t time.Time) ([]*Certificate, error) {
+	chain, err := v.Verify(key, UsageSign, t)
+	if err != nil {
+		return nil, err
+	}
+	return chain, key.Verify(message, signature)
+}
+
+func (v *Verifier) Revoked(key *sv.PublicKey, t time.Time) bool {
+	if v.revokedBy(key, key, t) {
+		return true
+	}
+	for _, root := range v.Roots {
+		if v.revokedBy(key, root, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Verifier) verify(key *sv.PublicKey, usage Usage, t time.Time, depth int) ([]*Certificate, error) {
+	if v.Revoked(key, t) {
+		return nil, ErrRevoked
+	}
+	if v.isRoot(key) {
+		return nil, nil
+	}
+	err := ErrUntrusted
+	if depth >= MaxDepth {
+		return nil, err
+	}
+	for _, c := range v.Certificates {
+		if !c.Subject.Equal(key) || c.Usage&usage != usage || !c.Valid(t) || c.CheckSignature() != nil {
+			continue
+		}
+		if v.revokedBy(key, c.Issuer, t) {
+			err = ErrRevoked
+			continue
+		}
+		chain, e := v.verify(c.Issuer, UsageCertify, t, depth+1)
+		if e != nil {
+			if errors.Is(e, ErrRevoked) {
+				err = e
+			}
+			continue
+		}
+		return append([]*Certificate{c}, chain...), nil
+	}
+	return nil, err
+}
+
+func (v *Verifier) revokedBy(key, issuer *sv.PublicKey, t time.Time) bool {
+	for _, r := range v.Revocations {
+		if r.Key.Equal(key) && r.Issuer.Equal(issuer) && !r.Time.After(t) && r.CheckSignature() == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Verifier) isRoot(key *sv.PublicKey) bool {
+	for _, root := range v.Roots {
+		if root.Equal(key) {
+			return true
+		}
+	}
+	return false
+}
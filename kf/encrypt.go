@@ -0,0 +1,76 @@
+package kf
+
+import (
+	"bytes"
+	"encoding/pem"
+	"errors"
+
+	"github.com/jamesliu96/geheim"
+)
+
+const TypeEncryptedPrivate = "GEHEIM ENCRYPTED PRIVATE KEY"
+
+var (
+	ErrEncrypted  = errors.New("kf: private key is encrypted")
+	ErrPassphrase = errors.New("kf: empty passphrase")
+)
+
+func IsEncrypted(data []byte) bool {
+	block, _ := pem.Decode(data)
+	return block != nil && (block.Type == TypeEncryptedPrivate || block.Type == TypeOpenSSHPrivate && isSSHEncrypted(block))
+}
+
+func EncryptPrivateKey(data, passphrase []byte, kdf geheim.KDF, sec int) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrPassphrase
+	}
+	if kdf == geheim.HKDF {
+		return nil, geheim.ErrKDF
+	}
+	if _, err := ParsePrivateKey(data); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if _, err := geheim.EncryptArchive(bytes.NewReader(data), &buf, passphrase, int64(len(data)), geheim.DefaultCipher, geheim.DefaultHash, kdf, sec, nil); err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: TypeEncryptedPrivate, Bytes: buf.Bytes()}), nil
+}
+
+func DecryptPrivateKey(data, passphrase []byte) ([]byte, error) {
+	block, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == TypeOpenSSHPrivate {
+		key, err := parseSSHPrivateKey(data, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return MarshalPrivateKey(key)
+	}
+	if block.Type != TypeEncryptedPrivate {
+		return nil, ErrPEM
+	}
+	var buf bytes.Buffer
+	if _, _, err := geheim.DecryptArchive(bytes.NewReader(block.Bytes), &buf, passphrase, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func MarshalEncryptedPrivateKey(key PrivateKey, passphrase []byte, kdf geheim.KDF, sec int) ([]byte, error) {
+	data, err := MarshalPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptPrivateKey(data, passphrase, kdf, sec)
+}
+
+func ParseEncryptedPrivateKey(data, passphrase []byte) (PrivateKey, error) {
+	data, err := DecryptPrivateKey(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKey(data)
+}
@@ -0,0 +1,48 @@
+package kf
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strings"
+
+	"github.com/jamesliu96/geheim/internal/bech32"
+)
+
+const (
+	HRPPublic       = "ghmpub"
+	FingerprintSize = sha256.Size
+)
+
+var ErrBech32 = errors.New("kf: malformed bech32 string")
+
+func Fingerprint(key PublicKey) ([]byte, error) {
+	a, err := AlgorithmOf(key)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write([]byte{byte(a)})
+	h.Write(key.Bytes())
+	return h.Sum(nil), nil
+}
+
+func EncodePublicKey(key PublicKey) (string, error) {
+	a, err := AlgorithmOf(key)
+	if err != nil {
+		return "", err
+	}
+	return bech32.Encode(bech32.Bech32m, HRPPublic, append([]byte{byte(a)}, key.Bytes()...))
+}
+
+func DecodePublicKey(s string) (PublicKey, error) {
+	hrp, data, err := bech32.Decode(bech32.Bech32m, s)
+	if err != nil {
+		return nil, ErrBech32
+	}
+	if hrp != HRPPublic || len(data) < 1 {
+		return nil, ErrBech32
+	}
+	return NewPublicKey(Algorithm(data[0]), data[1:])
+}
+
+func IsEncodedPublicKey(s string) bool { return strings.HasPrefix(strings.ToLower(s), HRPPublic+"1") }
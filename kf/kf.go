@@ -0,0 +1,416 @@
+package kf
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xp"
+)
+
+type Algorithm int
+
+type PrivateKey interface {
+	Bytes() []byte
+	Equal(crypto.PrivateKey) bool
+	Public() crypto.PublicKey
+}
+
+type PublicKey interface {
+	Bytes() []byte
+	Equal(crypto.PublicKey) bool
+}
+
+const (
+	X25519 Algorithm = 1 + iota
+	P256
+	P384
+	P521
+	MLKEM768
+	Ed25519
+)
+
+var AlgorithmNames = map[Algorithm]string{
+	X25519:   "X25519",
+	P256:     "P-256",
+	P384:     "P-384",
+	P521:     "P-521",
+	MLKEM768: "ML-KEM-768",
+	Ed25519:  "Ed25519",
+}
+
+var algorithms = [...]Algorithm{
+	X25519,
+	P256,
+	P384,
+	P521,
+	MLKEM768,
+	Ed25519,
+}
+
+var AlgorithmString = func() string {
+	d := make([]string, len(algorithms))
+	for i, a := range algorithms {
+		d[i] = fmt.Sprintf("%d:%s", a, AlgorithmNames[a])
+	}
+	return strings.Join(d, ", ")
+}()
+
+var PrivateSizes = map[Algorithm]int{
+	X25519:   xp.PrivateSizes[xp.X25519],
+	P256:     xp.PrivateSizes[xp.P256],
+	P384:     xp.PrivateSizes[xp.P384],
+	P521:     xp.PrivateSizes[xp.P521],
+	MLKEM768: xp.KEMSeedSize,
+	Ed25519:  sv.PrivateSize,
+}
+
+var SeedSizes = map[Algorithm]int{
+	X25519:   xp.PrivateSizes[xp.X25519],
+	P256:     xp.PrivateSizes[xp.P256],
+	P384:     xp.PrivateSizes[xp.P384],
+	P521:     xp.PrivateSizes[xp.P521],
+	MLKEM768: xp.KEMSeedSize,
+	Ed25519:  sv.SeedSize,
+}
+
+var PublicSizes = map[Algorithm]int{
+	X25519:   xp.PublicSizes[xp.X25519],
+	P256:     xp.PublicSizes[xp.P256],
+	P384:     xp.PublicSizes[xp.P384],
+	P521:     xp.PublicSizes[xp.P521],
+	MLKEM768: xp.KEMPublicSize,
+	Ed25519:  sv.PublicSize,
+}
+
+var curveAlgorithms = map[xp.Curve]Algorithm{
+	xp.X25519: X25519,
+	xp.P256:   P256,
+	xp.P384:   P384,
+	xp.P521:   P521,
+}
+
+var algorithmCurves = map[Algorithm]xp.Curve{
+	X25519: xp.X25519,
+	P256:   xp.P256,
+	P384:   xp.P384,
+	P521:   xp.P521,
+}
+
+const (
+	TypePrivate = "PRIVATE KEY"
+	TypePublic  = "PUBLIC KEY"
+
+	TypeGeheimPrivate = "GEHEIM PRIVATE KEY"
+	TypeGeheimPublic  = "GEHEIM PUBLIC KEY"
+
+	HeaderAlgorithm = "Algorithm"
+)
+
+const (
+	pemBegin = "-----BEGIN "
+	pemEnd   = "-----END "
+)
+
+var (
+	ErrAlgorithm = fmt.Errorf("kf: invalid algorithm (%s)", AlgorithmString)
+	ErrKey       = errors.New("kf: unsupported key type")
+	ErrPEM       = errors.New("kf: malformed pem")
+)
+
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, a := range algorithms {
+		if AlgorithmNames[a] == name {
+			return a, nil
+		}
+	}
+	return 0, ErrAlgorithm
+}
+
+func AlgorithmOf(key any) (Algorithm, error) {
+	switch k := key.(type) {
+	case *xp.PrivateKey:
+		return curveAlgorithms[k.Curve()], nil
+	case *xp.PublicKey:
+		return curveAlgorithms[k.Curve()], nil
+	case *xp.KEMPrivateKey, *xp.KEMPublicKey:
+		return MLKEM768, nil
+	case *sv.PrivateKey, *sv.PublicKey:
+		return Ed25519, nil
+	}
+	return 0, ErrKey
+}
+
+func GenerateKey(algorithm Algorithm) (PrivateKey, error) {
+	switch algorithm {
+	case X25519, P256, P384, P521:
+		return xp.GenerateKey(algorithmCurves[algorithm])
+	case MLKEM768:
+		return xp.GenerateKEMKey()
+	case Ed25519:
+		return sv.GenerateKey()
+	}
+	return nil, ErrAlgorithm
+}
+
+func NewPrivateKey(algorithm Algorithm, key []byte) (PrivateKey, error) {
+	switch algorithm {
+	case X25519, P256, P384, P521:
+		return xp.NewPrivateKey(algorithmCurves[algorithm], key)
+	case MLKEM768:
+		return xp.NewKEMPrivateKey(key)
+	case Ed25519:
+		return sv.NewPrivateKey(key)
+	}
+	return nil, ErrAlgorithm
+}
+
+func NewPublicKey(algorithm Algorithm, key []byte) (PublicKey, error) {
+	switch algorithm {
+	case X25519, P256, P384, P521:
+		return xp.NewPublicKey(algorithmCurves[algorithm], key)
+	case MLKEM768:
+		return xp.NewKEMPublicKey(key)
+	case Ed25519:
+		return sv.NewPublicKey(key)
+	}
+	return nil, ErrAlgorithm
+}
+
+func Seed(key PrivateKey) ([]byte, error) {
+	switch k := key.(type) {
+	case *xp.PrivateKey, *xp.KEMPrivateKey:
+		return k.Bytes(), nil
+	case *sv.PrivateKey:
+		return k.Seed(), nil
+	}
+	return nil, ErrKey
+}
+
+func NewKeyFromSeed(algorithm Algorithm, seed []byte) (PrivateKey, error) {
+	if algorithm == Ed25519 {
+		return sv.NewKeyFromSeed(seed)
+	}
+	return NewPrivateKey(algorithm, seed)
+}
+
+func Public(key PrivateKey) (PublicKey, error) {
+	switch k := key.(type) {
+	case *xp.PrivateKey:
+		return k.PublicKey(), nil
+	case *xp.KEMPrivateKey:
+		return k.PublicKey(), nil
+	case *sv.PrivateKey:
+		return k.PublicKey(), nil
+	}
+	return nil, ErrKey
+}
+
+func IsPEM(data []byte) bool { return bytes.HasPrefix(data, []byte(pemBegin)) }
+
+func PeekPEM(r *bufio.Reader) bool {
+	b, _ := r.Peek(len(pemBegin))
+	return IsPEM(b)
+}
+
+func ReadKey(r *bufio.Reader, size int) (data []byte, err error) {
+	if b, _ := r.Peek(len(prefixSSH)); string(b) == prefixSSH {
+		data, err = r.ReadBytes('\n')
+		if err == io.EOF && len(data) > 0 {
+			err = nil
+		}
+		return
+	}
+	if PeekPEM(r) {
+		for {
+			line, err := r.ReadBytes('\n')
+			data = append(data, line...)
+			if bytes.HasPrefix(line, []byte(pemEnd)) {
+				return data, nil
+			}
+			if err == io.EOF {
+				return nil, ErrPEM
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	}
+	data = make([]byte, size)
+	_, err = io.ReadFull(r, data)
+	return
+}
+
+func MarshalPrivateKey(key PrivateKey) ([]byte, error) {
+	block, err := encodePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+func MarshalPublicKey(key PublicKey) ([]byte, error) {
+	block, err := encodePublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+func ParsePrivateKey(data []byte) (PrivateKey, error) {
+	block, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return decodePrivateKey(block)
+}
+
+func ParsePublicKey(data []byte) (PublicKey, error) {
+	if !IsPEM(data) && IsSSHPublicKey(data) {
+		keys, err := ParseAuthorizedKeys(data)
+		if err != nil {
+			return nil, err
+		}
+		return keys[0], nil
+	}
+	block, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return decodePublicKey(block)
+}
+
+func decode(data []byte) (*pem.Block, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrPEM
+	}
+	return block, nil
+}
+
+func encodePrivateKey(key PrivateKey) (*pem.Block, error) {
+	var k any
+	switch key := key.(type) {
+	case *xp.PrivateKey:
+		k = key.ECDHPrivateKey()
+	case *sv.PrivateKey:
+		k = ed25519.PrivateKey(key.Bytes())
+	case *xp.KEMPrivateKey:
+		return &pem.Block{
+			Type:    TypeGeheimPrivate,
+			Headers: map[string]string{HeaderAlgorithm: AlgorithmNames[MLKEM768]},
+			Bytes:   key.Bytes(),
+		}, nil
+	default:
+		return nil, ErrKey
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k)
+	if err != nil {
+		return nil, err
+	}
+	return &pem.Block{Type: TypePrivate, Bytes: der}, nil
+}
+
+func encodePublicKey(key PublicKey) (*pem.Block, error) {
+	var k any
+	switch key := key.(type) {
+	case *xp.PublicKey:
+		k = key.ECDHPublicKey()
+	case *sv.PublicKey:
+		k = ed25519.PublicKey(key.Bytes())
+	case *xp.KEMPublicKey:
+		return &pem.Block{
+			Type:    TypeGeheimPublic,
+			Headers: map[string]string{HeaderAlgorithm: AlgorithmNames[MLKEM768]},
+			Bytes:   key.Bytes(),
+		}, nil
+	default:
+		return nil, ErrKey
+	}
+	der, err := x509.MarshalPKIXPublicKey(k)
+	if err != nil {
+		return nil, err
+	}
+	return &pem.Block{Type: TypePublic, Bytes: der}, nil
+}
+
+func decodePrivateKey(block *pem.Block) (PrivateKey, error) {
+	switch block.Type {
+	case TypeEncryptedPrivate:
+		return nil, ErrEncrypted
+	case TypeOpenSSHPrivate:
+		return parseSSHPrivateKey(pem.EncodeToMemory(block), nil)
+	case TypePrivate:
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch k := k.(type) {
+		case ed25519.PrivateKey:
+			return sv.NewPrivateKey(k)
+		case *ecdsa.PrivateKey:
+			kk, err := k.ECDH()
+			if err != nil {
+				return nil, err
+			}
+			return xp.NewECDHPrivateKey(kk)
+		case *ecdh.PrivateKey:
+			return xp.NewECDHPrivateKey(k)
+		}
+		return nil, ErrKey
+	case TypeGeheimPrivate:
+		a, err := ParseAlgorithm(block.Headers[HeaderAlgorithm])
+		if err != nil {
+			return nil, err
+		}
+		switch a {
+		case MLKEM768:
+			return xp.NewKEMPrivateKey(block.Bytes)
+		}
+		return nil, ErrKey
+	}
+	return nil, fmt.Errorf("kf: unexpected pem type %q", block.Type)
+}
+
+func decodePublicKey(block *pem.Block) (PublicKey, error) {
+	switch block.Type {
+	case TypePublic:
+		k, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch k := k.(type) {
+		case ed25519.PublicKey:
+			return sv.NewPublicKey(k)
+		case *ecdsa.PublicKey:
+			kk, err := k.ECDH()
+			if err != nil {
+				return nil, err
+			}
+			return xp.NewECDHPublicKey(kk)
+		case *ecdh.PublicKey:
+			return xp.NewECDHPublicKey(k)
+		}
+		return nil, ErrKey
+	case TypeGeheimPublic:
+		a, err := ParseAlgorithm(block.Headers[HeaderAlgorithm])
+		if err != nil {
+			return nil, err
+		}
+		switch a {
+		case MLKEM768:
+			return xp.NewKEMPublicKey(block.Bytes)
+		}
+		return nil, ErrKey
+	}
+	return nil, fmt.Errorf("kf: unexpected pem type %q", block.Type)
+}
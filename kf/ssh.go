@@ -0,0 +1,73 @@
+package kf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/pem"
+	"errors"
+	"strings"
+
+	"github.com/jamesliu96/geheim/sv"
+	"golang.org/x/crypto/ssh"
+)
+
+const TypeOpenSSHPrivate = "OPENSSH PRIVATE KEY"
+
+const prefixSSH = "ssh-"
+
+func IsSSHPublicKey(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(prefixSSH)) || bytes.Contains(data, []byte(" "+ssh.KeyAlgoED25519+" "))
+}
+
+func ParseAuthorizedKeys(data []byte) (keys []PublicKey, err error) {
+	for line := range strings.Lines(string(data)) {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var public ssh.PublicKey
+		if public, _, _, _, err = ssh.ParseAuthorizedKey([]byte(line)); err != nil {
+			return
+		}
+		if public.Type() != ssh.KeyAlgoED25519 {
+			continue
+		}
+		var key *sv.PublicKey
+		if key, err = sv.NewPublicKey(public.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey)); err != nil {
+			return
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		err = ErrKey
+	}
+	return
+}
+
+func parseSSHPrivateKey(data, passphrase []byte) (PrivateKey, error) {
+	var (
+		key any
+		err error
+	)
+	if passphrase != nil {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(data, passphrase)
+	} else {
+		key, err = ssh.ParseRawPrivateKey(data)
+	}
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, ErrEncrypted
+		}
+		return nil, err
+	}
+	k, ok := key.(*ed25519.PrivateKey)
+	if !ok {
+		return nil, ErrKey
+	}
+	return sv.NewPrivateKey(*k)
+}
+
+func isSSHEncrypted(block *pem.Block) bool {
+	_, err := parseSSHPrivateKey(pem.EncodeToMemory(block), nil)
+	return errors.Is(err, ErrEncrypted)
+}
@@ -0,0 +1,261 @@
+package ks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jamesliu96/geheim/ag"
+	"github.com/jamesliu96/geheim/kf"
+)
+
+const EnvDir = "GEHEIM_KEYSTORE"
+
+const (
+	dirIdentities = "identities"
+	dirContacts   = "contacts"
+
+	extPrivate = ".key"
+	extPublic  = ".pub"
+)
+
+var (
+	ErrName     = errors.New("ks: invalid name")
+	ErrExist    = errors.New("ks: name already exists")
+	ErrNotExist = errors.New("ks: name does not exist")
+	ErrPlain    = errors.New("ks: identity must be encrypted")
+)
+
+var nameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+type Store struct{ Dir string }
+
+type Entry struct {
+	Name        string
+	Identity    bool
+	Algorithm   kf.Algorithm
+	Fingerprint []byte
+}
+
+func Default() (*Store, error) {
+	dir := os.Getenv(EnvDir)
+	if dir == "" {
+		config, err := os.UserConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(config, "geheim")
+	}
+	return Open(dir)
+}
+
+func Open(dir string) (*Store, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{dir}, nil
+}
+
+func (s *Store) List() (entries []Entry, err error) {
+	for _, d := range []string{dirIdentities, dirContacts} {
+		var files []fs.DirEntry
+		if files, err = os.ReadDir(filepath.Join(s.Dir, d)); errors.Is(err, fs.ErrNotExist) {
+			err = nil
+			continue
+		} else if err != nil {
+			return
+		}
+		for _, file := range files {
+			name, ok := strings.CutSuffix(file.Name(), extPublic)
+			if !ok || file.IsDir() {
+				continue
+			}
+			var public kf.PublicKey
+			if public, err = s.publicKey(d, name); err != nil {
+				return
+			}
+			entry := Entry{Name: name, Identity: d == dirIdentities}
+			if entry.Algorithm, err = kf.AlgorithmOf(public); err != nil {
+				return
+			}
+			if entry.Fingerprint, err = kf.Fingerprint(public); err != nil {
+				return
+			}
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.Name, b.Name) })
+	return
+}
+
+func (s *Store) ImportIdentity(name string, data []byte, public kf.PublicKey) error {
+	if !kf.IsEncrypted(data) {
+		return ErrPlain
+	}
+	if err := s.checkNew(name); err != nil {
+		return err
+	}
+	pub, err := kf.MarshalPublicKey(public)
+	if err != nil {
+		return err
+	}
+	if err := s.write(dirIdentities, name, extPrivate, data); err != nil {
+		return err
+	}
+	return s.write(dirIdentities, name, extPublic, pub)
+}
+
+func (s *Store) ImportContact(name string, public kf.PublicKey) error {
+	if err := s.checkNew(name); err != nil {
+		return err
+	}
+	pub, err := kf.MarshalPublicKey(public)
+	if err != nil {
+		return err
+	}
+	return s.write(dirContacts, name, extPublic, pub)
+}
+
+func (s *Store) Identity(name string) ([]byte, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, ErrName
+	}
+	data, err := os.ReadFile(s.path(dirIdentities, name, extPrivate))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotExist, name)
+	}
+	return data, err
+}
+
+func (s *Store) Public(name string) (kf.PublicKey, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, ErrName
+	}
+	for _, d := range []string{dirIdentities, dirContacts} {
+		public, err := s.publicKey(d, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return public, err
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotExist, name)
+}
+
+func (s *Store) ResolveIdentity(arg string) ([]byte, error) {
+	if !nameRegexp.MatchString(arg) {
+		return os.ReadFile(arg)
+	}
+	data, err := s.Identity(arg)
+	if errors.Is(err, ErrNotExist) {
+		if d, e := os.ReadFile(arg); !errors.Is(e, fs.ErrNotExist) {
+			return d, e
+		}
+	}
+	return data, err
+}
+
+func (s *Store) ResolveRecipient(arg string) (kf.PublicKey, error) {
+	keys, err := s.ResolveRecipients(arg)
+	if err != nil {
+		return nil, err
+	}
+	return keys[0], nil
+}
+
+func (s *Store) ResolveRecipients(arg string) ([]kf.PublicKey, error) {
+	if kf.IsEncodedPublicKey(arg) {
+		return one(kf.DecodePublicKey(arg))
+	}
+	if ag.IsRecipient(arg) {
+		return one(ag.DecodeRecipient(arg))
+	}
+	if kf.IsSSHPublicKey([]byte(arg)) {
+		return kf.ParseAuthorizedKeys([]byte(arg))
+	}
+	if !nameRegexp.MatchString(arg) {
+		return readRecipients(arg)
+	}
+	key, err := s.Public(arg)
+	if errors.Is(err, ErrNotExist) {
+		if keys, e := readRecipients(arg); !errors.Is(e, fs.ErrNotExist) {
+			return keys, e
+		}
+	}
+	return one(key, err)
+}
+
+func readRecipients(name string) ([]kf.PublicKey, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if !kf.IsPEM(data) && kf.IsSSHPublicKey(data) {
+		return kf.ParseAuthorizedKeys(data)
+	}
+	return one(kf.ParsePublicKey(data))
+}
+
+func one[T kf.PublicKey](key T, err error) ([]kf.PublicKey, error) {
+	if err != nil {
+		return nil, err
+	}
+	return []kf.PublicKey{key}, nil
+}
+
+func (s *Store) Delete(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return ErrName
+	}
+	var found bool
+	for _, p := range []string{
+		s.path(dirIdentities, name, extPrivate),
+		s.path(dirIdentities, name, extPublic),
+		s.path(dirContacts, name, extPublic),
+	} {
+		err := os.Remove(p)
+		if err == nil {
+			found = true
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrNotExist, name)
+	}
+	return nil
+}
+
+func (s *Store) checkNew(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return ErrName
+	}
+	if _, err := s.Public(name); err == nil {
+		return fmt.Errorf("%w: %s", ErrExist, name)
+	} else if !errors.Is(err, ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) publicKey(dir, name string) (kf.PublicKey, error) {
+	data, err := os.ReadFile(s.path(dir, name, extPublic))
+	if err != nil {
+		return nil, err
+	}
+	return kf.ParsePublicKey(data)
+}
+
+func (s *Store) write(dir, name, ext string, data []byte) error {
+	if err := os.MkdirAll(filepath.Join(s.Dir, dir), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(dir, name, ext), data, 0o600)
+}
+
+func (s *Store) path(dir, name, ext string) string { return filepath.Join(s.Dir, dir, name+ext) }
@@ -0,0 +1,220 @@
+package mf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jamesliu96/geheim"
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/sv"
+)
+
+const DefaultHash = geheim.SHA_256
+
+const (
+	header  = "geheim manifest"
+	context = "geheim/mf"
+
+	prefixHash      = "hash "
+	prefixKey       = "key "
+	prefixSignature = "signature "
+)
+
+type Status int
+
+const (
+	Modified Status = 1 + iota
+	Missing
+	Extra
+)
+
+var StatusNames = map[Status]string{
+	Modified: "modified",
+	Missing:  "missing",
+	Extra:    "extra",
+}
+
+var (
+	ErrFile     = errors.New("mf: unsupported file")
+	ErrFormat   = errors.New("mf: malformed manifest")
+	ErrKey      = errors.New("mf: signer mismatch")
+	ErrModified = errors.New("mf: tree does not match manifest")
+)
+
+type Entry struct {
+	Path   string
+	Size   int64
+	Digest []byte
+}
+
+type Manifest struct {
+	Hash    geheim.Hash
+	Entries []Entry
+}
+
+type Difference struct {
+	Path   string
+	Status Status
+}
+
+func (d Difference) String() string { return fmt.Sprintf("%s %s", StatusNames[d.Status], d.Path) }
+
+func Build(fsys fs.FS, hash geheim.Hash) (*Manifest, error) {
+	h, err := geheim.GetHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{Hash: hash}
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if strings.ContainsAny(path, "\r\n") {
+			return fmt.Errorf("%w: %s", ErrFile, path)
+		}
+		if !d.Type().IsRegular() {
+			var target string
+			if d.Type()&fs.ModeSymlink != 0 {
+				if target, err = fs.ReadLink(fsys, path); err != nil {
+					return err
+				}
+			}
+			hh := h()
+			io.WriteString(hh, d.Type().String()+" "+target)
+			m.Entries = append(m.Entries, Entry{path, -1, hh.Sum(nil)})
+			return nil
+		}
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		hh := h()
+		size, err := io.Copy(hh, f)
+		if err != nil {
+			return err
+		}
+		m.Entries = append(m.Entries, Entry{path, size, hh.Sum(nil)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Manifest) Sign(key *sv.PrivateKey) ([]byte, error) {
+	enc, err := kf.EncodePublicKey(key.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	body := m.marshal(enc)
+	signature, err := key.SignMode(sv.Context, bytes.NewReader(body), context)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(body, "%s%x\n", prefixSignature, signature), nil
+}
+
+func Parse(data []byte, public *sv.PublicKey) (*Manifest, error) {
+	i := bytes.LastIndex(data, []byte("\n"+prefixSignature))
+	if i < 0 {
+		return nil, ErrFormat
+	}
+	body := data[:i+1]
+	signature, err := hex.DecodeString(strings.TrimSpace(string(data[i+1+len(prefixSignature):])))
+	if err != nil {
+		return nil, ErrFormat
+	}
+	lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+	if len(lines) < 3 || lines[0] != header ||
+		!strings.HasPrefix(lines[1], prefixHash) ||
+		!strings.HasPrefix(lines[2], prefixKey) {
+		return nil, ErrFormat
+	}
+	signer, err := kf.DecodePublicKey(strings.TrimPrefix(lines[2], prefixKey))
+	if err != nil {
+		return nil, err
+	}
+	if !public.Equal(signer) {
+		return nil, ErrKey
+	}
+	if err := public.VerifyMode(sv.Context, bytes.NewReader(body), signature, context); err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	name := strings.TrimPrefix(lines[1], prefixHash)
+	for h, n := range geheim.HashNames {
+		if n == name {
+			m.Hash = h
+		}
+	}
+	if m.Hash == 0 {
+		return nil, geheim.ErrHash
+	}
+	for _, line := range lines[3:] {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			return nil, ErrFormat
+		}
+		digest, err := hex.DecodeString(fields[0])
+		if err != nil {
+			return nil, ErrFormat
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, ErrFormat
+		}
+		m.Entries = append(m.Entries, Entry{fields[2], size, digest})
+	}
+	return m, nil
+}
+
+func (m *Manifest) Verify(fsys fs.FS) ([]Difference, error) {
+	actual, err := Build(fsys, m.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(m, actual), nil
+}
+
+func Compare(expected, actual *Manifest) (d []Difference) {
+	entries := make(map[string]Entry, len(actual.Entries))
+	for _, e := range actual.Entries {
+		entries[e.Path] = e
+	}
+	for _, e := range expected.Entries {
+		a, ok := entries[e.Path]
+		if !ok {
+			d = append(d, Difference{e.Path, Missing})
+			continue
+		}
+		delete(entries, e.Path)
+		if a.Size != e.Size || !bytes.Equal(a.Digest, e.Digest) {
+			d = append(d, Difference{e.Path, Modified})
+		}
+	}
+	for path := range entries {
+		d = append(d, Difference{path, Extra})
+	}
+	slices.SortFunc(d, func(a, b Difference) int { return strings.Compare(a.Path, b.Path) })
+	return
+}
+
+func (m *Manifest) marshal(enc string) []byte {
+	b := fmt.Appendf(nil, "%s\n%s%s\n%s%s\n", header, prefixHash, geheim.HashNames[m.Hash], prefixKey, enc)
+	for _, e := range m.Entries {
+		b = fmt.Appendf(b, "%x %d %s\n", e.Digest, e.Size, e.Path)
+	}
+	return b
+}
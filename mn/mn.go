@@ -0,0 +1,136 @@
+package mn
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//go:embed english.txt
+var english string
+
+var Words = strings.Fields(english)
+
+var wordIndex = func() map[string]int {
+	d := make(map[string]int, len(Words))
+	for i, w := range Words {
+		d[w] = i
+	}
+	return d
+}()
+
+const (
+	MinChunkSize = 16
+	MaxChunkSize = 32
+	MaxWords     = MaxChunkSize * 8 * 33 / 32 / 11
+)
+
+var (
+	ErrEntropy  = errors.New("mn: invalid entropy size")
+	ErrWords    = errors.New("mn: invalid number of words")
+	ErrChecksum = errors.New("mn: checksum mismatch")
+)
+
+func Encode(entropy []byte) ([]string, error) {
+	var d []string
+	for len(entropy) > 0 {
+		n := min(len(entropy), MaxChunkSize)
+		words, err := encodeChunk(pad(entropy[:n]))
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, words...)
+		entropy = entropy[n:]
+	}
+	if len(d) == 0 {
+		return nil, ErrEntropy
+	}
+	return d, nil
+}
+
+func Decode(words []string) ([]byte, error) {
+	var d []byte
+	for len(words) > 0 {
+		n := min(len(words), MaxWords)
+		entropy, err := decodeChunk(words[:n])
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, entropy...)
+		words = words[n:]
+	}
+	if len(d) == 0 {
+		return nil, ErrWords
+	}
+	return d, nil
+}
+
+func Parse(s string) []string { return strings.Fields(strings.ToLower(s)) }
+
+func pad(chunk []byte) []byte {
+	size := max(MinChunkSize, (len(chunk)+3)/4*4)
+	return append(chunk[:len(chunk):len(chunk)], make([]byte, size-len(chunk))...)
+}
+
+func encodeChunk(entropy []byte) ([]string, error) {
+	size := len(entropy)
+	if size < MinChunkSize || size > MaxChunkSize || size%4 != 0 {
+		return nil, ErrEntropy
+	}
+	sum := sha256.Sum256(entropy)
+	bits := append(append([]byte(nil), entropy...), sum[0])
+	count := size * 8 * 33 / 32 / 11
+	d := make([]string, count)
+	for i := range d {
+		d[i] = Words[readBits(bits, i*11)]
+	}
+	return d, nil
+}
+
+func decodeChunk(words []string) ([]byte, error) {
+	count := len(words)
+	if count%3 != 0 || count < MinChunkSize*8*33/32/11 || count > MaxWords {
+		return nil, ErrWords
+	}
+	bits := make([]byte, (count*11+7)/8)
+	for i, w := range words {
+		index, ok := wordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("mn: unknown word %q", w)
+		}
+		writeBits(bits, i*11, index)
+	}
+	size := count * 11 * 32 / 33 / 8
+	entropy := bits[:size]
+	checksumBits := size / 4
+	sum := sha256.Sum256(entropy)
+	if bits[size]>>(8-checksumBits) != sum[0]>>(8-checksumBits) {
+		return nil, ErrChecksum
+	}
+	return entropy, nil
+}
+
+func readBits(b []byte, offset int) (v int) {
+	for i := range 11 {
+		bit := offset + i
+		v = v<<1 | int(b[bit/8]>>(7-bit%8)&1)
+	}
+	return
+}
+
+func writeBits(b []byte, offset, v int) {
+	for i := range 11 {
+		bit := offset + i
+		if v>>(10-i)&1 == 1 {
+			b[bit/8] |= 1 << (7 - bit%8)
+		}
+	}
+}
+
+func Seed(words []string, passphrase string) ([]byte, error) {
+	return pbkdf2.Key(sha512.New, strings.Join(words, " "), []byte("mnemonic"+passphrase), 2048, 64)
+}
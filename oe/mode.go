@@ -0,0 +1,91 @@
+package oe
+
+import (
+	"crypto/cipher"
+	"io"
+)
+
+const (
+	modeCBC = iota
+	modeCFB
+	modeCTR
+	modeECB
+	modeOFB
+)
+
+const chunkSize = 1 << 16
+
+func mode(c Cipher) int { return int(c-1) / 3 }
+
+func newStream(block cipher.Block, iv []byte, c Cipher, encrypt bool) cipher.Stream {
+	switch mode(c) {
+	case modeCFB:
+		if encrypt {
+			return cipher.NewCFBEncrypter(block, iv)
+		}
+		return cipher.NewCFBDecrypter(block, iv)
+	case modeOFB:
+		return cipher.NewOFB(block, iv)
+	}
+	return cipher.NewCTR(block, iv)
+}
+
+type ecb struct {
+	block cipher.Block
+	crypt func(dst, src []byte)
+}
+
+func (e ecb) BlockSize() int { return e.block.BlockSize() }
+
+func (e ecb) CryptBlocks(dst, src []byte) {
+	bs := e.block.BlockSize()
+	for i := 0; i < len(src); i += bs {
+		e.crypt(dst[i:i+bs], src[i:i+bs])
+	}
+}
+
+func cryptBlocks(r io.Reader, w io.Writer, mode cipher.BlockMode, encrypt bool) error {
+	bs := mode.BlockSize()
+	buf := make([]byte, chunkSize+2*bs)
+	n := 0
+	for {
+		m, err := io.ReadFull(r, buf[n:chunkSize+bs])
+		n += m
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		mode.CryptBlocks(buf[:chunkSize], buf[:chunkSize])
+		if _, err := w.Write(buf[:chunkSize]); err != nil {
+			return err
+		}
+		n = copy(buf, buf[chunkSize:n])
+	}
+	if encrypt {
+		pad := bs - n%bs
+		for i := range pad {
+			buf[n+i] = byte(pad)
+		}
+		n += pad
+		mode.CryptBlocks(buf[:n], buf[:n])
+	} else {
+		if n == 0 || n%bs != 0 {
+			return ErrPadding
+		}
+		mode.CryptBlocks(buf[:n], buf[:n])
+		pad := int(buf[n-1])
+		if pad == 0 || pad > bs {
+			return ErrPadding
+		}
+		for _, b := range buf[n-pad : n] {
+			if int(b) != pad {
+				return ErrPadding
+			}
+		}
+		n -= pad
+	}
+	_, err := w.Write(buf[:n])
+	return err
+}
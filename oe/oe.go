@@ -0,0 +1,313 @@
+package oe
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type Cipher int
+
+const (
+	AES_128_CBC Cipher = 1 + iota
+	AES_192_CBC
+	AES_256_CBC
+	AES_128_CFB
+	AES_192_CFB
+	AES_256_CFB
+	AES_128_CTR
+	AES_192_CTR
+	AES_256_CTR
+	AES_128_ECB
+	AES_192_ECB
+	AES_256_ECB
+	AES_128_OFB
+	AES_192_OFB
+	AES_256_OFB
+)
+
+var CipherNames = map[Cipher]string{
+	AES_128_CBC: "AES-128-CBC",
+	AES_192_CBC: "AES-192-CBC",
+	AES_256_CBC: "AES-256-CBC",
+	AES_128_CFB: "AES-128-CFB",
+	AES_192_CFB: "AES-192-CFB",
+	AES_256_CFB: "AES-256-CFB",
+	AES_128_CTR: "AES-128-CTR",
+	AES_192_CTR: "AES-192-CTR",
+	AES_256_CTR: "AES-256-CTR",
+	AES_128_ECB: "AES-128-ECB",
+	AES_192_ECB: "AES-192-ECB",
+	AES_256_ECB: "AES-256-ECB",
+	AES_128_OFB: "AES-128-OFB",
+	AES_192_OFB: "AES-192-OFB",
+	AES_256_OFB: "AES-256-OFB",
+}
+
+var ciphers = [...]Cipher{
+	AES_128_CBC,
+	AES_192_CBC,
+	AES_256_CBC,
+	AES_128_CFB,
+	AES_192_CFB,
+	AES_256_CFB,
+	AES_128_CTR,
+	AES_192_CTR,
+	AES_256_CTR,
+	AES_128_ECB,
+	AES_192_ECB,
+	AES_256_ECB,
+	AES_128_OFB,
+	AES_192_OFB,
+	AES_256_OFB,
+}
+
+type KDF int
+
+const (
+	EVP_BytesToKey KDF = 1 + iota
+	PBKDF2
+)
+
+var KDFNames = map[KDF]string{
+	EVP_BytesToKey: "EVP_BytesToKey",
+	PBKDF2:         "PBKDF2",
+}
+
+type Hash int
+
+const (
+	MD5 Hash = 1 + iota
+	SHA_1
+	SHA_224
+	SHA_256
+	SHA_384
+	SHA_512
+)
+
+var HashNames = map[Hash]string{
+	MD5:     "MD5",
+	SHA_1:   "SHA1",
+	SHA_224: "SHA224",
+	SHA_256: "SHA256",
+	SHA_384: "SHA384",
+	SHA_512: "SHA512",
+}
+
+const Magic = "Salted__"
+
+const (
+	SaltSize          = 8
+	DefaultIterations = 10000
+)
+
+var DefaultParams = Params{AES_256_CTR, PBKDF2, SHA_256, DefaultIterations}
+
+var (
+	ErrCipher  = errors.New("oe: invalid cipher")
+	ErrKDF     = errors.New("oe: invalid kdf")
+	ErrHash    = errors.New("oe: invalid hash")
+	ErrIter    = errors.New("oe: invalid iterations")
+	ErrHeader  = errors.New("oe: malformed header")
+	ErrPadding = errors.New("oe: bad decrypt")
+	ErrOption  = errors.New("oe: invalid option")
+)
+
+type Params struct {
+	Cipher     Cipher
+	KDF        KDF
+	Hash       Hash
+	Iterations int
+}
+
+func (p Params) String() string {
+	if p.KDF == PBKDF2 {
+		return fmt.Sprintf("%s %s %s %d", CipherNames[p.Cipher], KDFNames[p.KDF], HashNames[p.Hash], p.Iterations)
+	}
+	return fmt.Sprintf("%s %s %s", CipherNames[p.Cipher], KDFNames[p.KDF], HashNames[p.Hash])
+}
+
+func ParseParams(s string) (p Params, err error) {
+	p = Params{DefaultParams.Cipher, EVP_BytesToKey, DefaultParams.Hash, DefaultIterations}
+	fields := strings.FieldsFunc(s, func(c rune) bool { return c == ',' || unicode.IsSpace(c) })
+	for i := 0; i < len(fields); i++ {
+		name := strings.ToLower(strings.TrimLeft(fields[i], "-"))
+		name, value, ok := strings.Cut(name, "=")
+		if !ok && (name == "md" || name == "iter") {
+			if i++; i == len(fields) {
+				err = ErrOption
+				return
+			}
+			value = strings.ToLower(fields[i])
+		}
+		switch name {
+		case "salt":
+		case "pbkdf2":
+			p.KDF = PBKDF2
+		case "md":
+			if p.Hash = parseName(value, HashNames); p.Hash == 0 {
+				err = ErrHash
+				return
+			}
+		case "iter":
+			if p.Iterations, err = strconv.Atoi(value); err != nil || p.Iterations < 1 {
+				err = ErrIter
+				return
+			}
+			p.KDF = PBKDF2
+		default:
+			if p.Cipher = parseName(name, CipherNames); p.Cipher == 0 {
+				err = ErrOption
+				return
+			}
+		}
+	}
+	return
+}
+
+func parseName[T comparable](s string, names map[T]string) (t T) {
+	s = strings.ReplaceAll(s, "-", "")
+	for k, name := range names {
+		if strings.EqualFold(strings.ReplaceAll(name, "-", ""), s) {
+			return k
+		}
+	}
+	return
+}
+
+func IsSalted(b []byte) bool { return bytes.HasPrefix(b, []byte(Magic)) }
+
+func Encrypt(r io.Reader, w io.Writer, key []byte, p Params) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	salt := make([]byte, SaltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return
+	}
+	block, iv, err := deriveKey(key, salt, p)
+	if err != nil {
+		return
+	}
+	if _, err = w.Write(append([]byte(Magic), salt...)); err != nil {
+		return
+	}
+	switch mode(p.Cipher) {
+	case modeCBC:
+		return cryptBlocks(r, w, cipher.NewCBCEncrypter(block, iv), true)
+	case modeECB:
+		return cryptBlocks(r, w, ecb{block, block.Encrypt}, true)
+	}
+	_, err = io.Copy(&cipher.StreamWriter{S: newStream(block, iv, p.Cipher, true), W: w}, r)
+	return
+}
+
+func Decrypt(r io.Reader, w io.Writer, key []byte, p Params) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	header := make([]byte, len(Magic)+SaltSize)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	if !IsSalted(header) {
+		return ErrHeader
+	}
+	block, iv, err := deriveKey(key, header[len(Magic):], p)
+	if err != nil {
+		return
+	}
+	switch mode(p.Cipher) {
+	case modeCBC:
+		return cryptBlocks(r, w, cipher.NewCBCDecrypter(block, iv), false)
+	case modeECB:
+		return cryptBlocks(r, w, ecb{block, block.Decrypt}, false)
+	}
+	_, err = io.Copy(w, &cipher.StreamReader{S: newStream(block, iv, p.Cipher, false), R: r})
+	return
+}
+
+func getHash(h Hash) (func() hash.Hash, error) {
+	switch h {
+	case MD5:
+		return md5.New, nil
+	case SHA_1:
+		return sha1.New, nil
+	case SHA_224:
+		return sha256.New224, nil
+	case SHA_256:
+		return sha256.New, nil
+	case SHA_384:
+		return sha512.New384, nil
+	case SHA_512:
+		return sha512.New, nil
+	}
+	return nil, ErrHash
+}
+
+func deriveKey(key, salt []byte, p Params) (block cipher.Block, iv []byte, err error) {
+	if CipherNames[p.Cipher] == "" {
+		err = ErrCipher
+		return
+	}
+	h, err := getHash(p.Hash)
+	if err != nil {
+		return
+	}
+	keySize := [...]int{16, 24, 32}[(p.Cipher-1)%3]
+	ivSize := aes.BlockSize
+	if mode(p.Cipher) == modeECB {
+		ivSize = 0
+	}
+	var derived []byte
+	switch p.KDF {
+	case EVP_BytesToKey:
+		derived = bytesToKey(h, key, salt, keySize+ivSize)
+	case PBKDF2:
+		if p.Iterations < 1 {
+			err = ErrIter
+			return
+		}
+		if derived, err = pbkdf2.Key(h, string(key), salt, p.Iterations, keySize+ivSize); err != nil {
+			return
+		}
+	default:
+		err = ErrKDF
+		return
+	}
+	if block, err = aes.NewCipher(derived[:keySize]); err != nil {
+		return
+	}
+	iv = derived[keySize:]
+	return
+}
+
+func bytesToKey(h func() hash.Hash, key, salt []byte, size int) []byte {
+	var d, prev []byte
+	for len(d) < size {
+		hh := h()
+		hh.Write(prev)
+		hh.Write(key)
+		hh.Write(salt)
+		prev = hh.Sum(nil)
+		d = append(d, prev...)
+	}
+	return d[:size]
+}
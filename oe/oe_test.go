@@ -0,0 +1,125 @@
+package oe_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/jamesliu96/geheim/oe"
+)
+
+const (
+	password  = "password"
+	plaintext = "Example plaintext for openssl enc interop.\n"
+	header    = "53616c7465645f5f0102030405060708"
+)
+
+// printf '%s' "$plaintext" | openssl enc $options -S 0102030405060708 -pass pass:password
+var vectors = []struct{ options, ciphertext string }{
+	{"-aes-256-cbc -md md5", "601e62d61f1bb63b171b63b8e1b0fa5b861958e11c79935bb8dc5206a75895ef36c5df81cc9920bf51aa574a5e3c2879"},
+	{"-aes-128-cbc -md sha256", "239e000064dd8f80f7c0e6bebdfb15f3ce5d0079fd0b7401a67322d3cc825077e6e4421ab9d4af1338c4a8962604f5e4"},
+	{"-aes-192-ecb -md sha1", "0d6a590027b2b28612627ac5ef4323a2b3f78dc2e9eabf861e2af499e5d1c6a5330860de3f36a47e5c4a4fe1589fc81e"},
+	{"-aes-256-ctr", "2e915fae6e300d21ac40dfe7736c5df76f2c461e93c834f93df031ddd857e37ae99a54a3031c083578f8fb"},
+	{"-aes-256-ctr -pbkdf2", "043f199f6bb7742f3283bb1447eb3b59bfc76d3ffe3cb8d895f5549fe13324f4bec3c51b4180ec103a43c7"},
+	{"-aes-128-cfb -pbkdf2 -iter 1000 -md sha512", "8110b2ac8436950cbec139efb244b0ed833596bcef01d82d8b9887f8565260a33d1b153b02fd115586eb8b"},
+	{"-aes-256-ofb -iter 20000 -md sha384", "961168106d840e3a3fb35687b21c7ea0a8be8d789b661de2c2a83760f966c486c0fc541e31c4e768dceec6"},
+	{"-aes-192-cbc -pbkdf2 -md sha224", "c5e8b60b9df655971b0d3968790203db0d7bd95c2094c79ed69cd6a22d3ca4cafb55b71eb19f06c023e092f8d44302a7"},
+}
+
+func TestOpenSSL(t *testing.T) {
+	for _, v := range vectors {
+		p, err := oe.ParseParams(v.options)
+		if err != nil {
+			t.Fatalf("%s: %v", v.options, err)
+		}
+		ciphertext, err := hex.DecodeString(header + v.ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if err := oe.Decrypt(bytes.NewReader(ciphertext), &out, []byte(password), p); err != nil {
+			t.Fatalf("%s: %v", v.options, err)
+		}
+		if out.String() != plaintext {
+			t.Fatalf("%s: plaintext %q, want %q", v.options, out.String(), plaintext)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for cipher := range oe.CipherNames {
+		for _, kdf := range []oe.KDF{oe.EVP_BytesToKey, oe.PBKDF2} {
+			p := oe.Params{Cipher: cipher, KDF: kdf, Hash: oe.SHA_256, Iterations: 1000}
+			for _, size := range []int{0, 1, 15, 16, 17, 1000} {
+				data := bytes.Repeat([]byte{'a'}, size)
+				var ciphertext, out bytes.Buffer
+				if err := oe.Encrypt(bytes.NewReader(data), &ciphertext, []byte(password), p); err != nil {
+					t.Fatalf("%s: %v", p, err)
+				}
+				if !oe.IsSalted(ciphertext.Bytes()) {
+					t.Fatalf("%s: missing header", p)
+				}
+				if err := oe.Decrypt(&ciphertext, &out, []byte(password), p); err != nil {
+					t.Fatalf("%s: %v", p, err)
+				}
+				if !bytes.Equal(out.Bytes(), data) {
+					t.Fatalf("%s: %d bytes mismatch", p, size)
+				}
+			}
+		}
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	for _, c := range []struct {
+		options string
+		p       oe.Params
+	}{
+		{"", oe.Params{oe.DefaultParams.Cipher, oe.EVP_BytesToKey, oe.DefaultParams.Hash, oe.DefaultIterations}},
+		{"aes-128-cbc,md=md5", oe.Params{oe.AES_128_CBC, oe.EVP_BytesToKey, oe.MD5, oe.DefaultIterations}},
+		{"-aes-256-cbc -salt -pbkdf2", oe.Params{oe.AES_256_CBC, oe.PBKDF2, oe.DefaultParams.Hash, oe.DefaultIterations}},
+		{"-aes-192-ofb -iter 5 -md sha1", oe.Params{oe.AES_192_OFB, oe.PBKDF2, oe.SHA_1, 5}},
+	} {
+		p, err := oe.ParseParams(c.options)
+		if err != nil {
+			t.Fatalf("%q: %v", c.options, err)
+		}
+		if p != c.p {
+			t.Fatalf("%q: %v, want %v", c.options, p, c.p)
+		}
+	}
+	for _, c := range []struct {
+		options string
+		err     error
+	}{
+		{"-evp_bytestokey", oe.ErrOption},
+		{"-des", oe.ErrOption},
+		{"-md", oe.ErrOption},
+		{"-md whirlpool", oe.ErrHash},
+		{"-iter 0", oe.ErrIter},
+		{"-iter x", oe.ErrIter},
+	} {
+		if _, err := oe.ParseParams(c.options); !errors.Is(err, c.err) {
+			t.Fatalf("%q: %v, want %v", c.options, err, c.err)
+		}
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	p, err := oe.ParseParams(vectors[0].options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := hex.DecodeString(header + vectors[0].ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := oe.Decrypt(bytes.NewReader(ciphertext), &out, []byte("wrong"), p); !errors.Is(err, oe.ErrPadding) {
+		t.Fatalf("%v, want %v", err, oe.ErrPadding)
+	}
+	if err := oe.Decrypt(bytes.NewReader(ciphertext[8:]), &out, []byte(password), p); !errors.Is(err, oe.ErrHeader) {
+		t.Fatalf("%v, want %v", err, oe.ErrHeader)
+	}
+}
@@ -0,0 +1,230 @@
+package rp
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/jamesliu96/geheim"
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xp"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const Magic = 1195920722
+
+const FileKeySize = 32
+
+const (
+	label       = "geheim/rp"
+	wrappedSize = FileKeySize + chacha20poly1305.Overhead
+	maxStanzas  = 255
+)
+
+var (
+	ErrHeader    = errors.New("rp: malformed header")
+	ErrRecipient = errors.New("rp: no recipients")
+	ErrIdentity  = errors.New("rp: no matching identity")
+)
+
+type stanza struct {
+	algorithm kf.Algorithm
+	share     []byte
+	wrapped   []byte
+}
+
+func Wrap(w io.Writer, recipients []kf.PublicKey) (fileKey []byte, err error) {
+	if len(recipients) == 0 || len(recipients) > maxStanzas {
+		err = ErrRecipient
+		return
+	}
+	fileKey = make([]byte, FileKeySize)
+	if _, err = rand.Read(fileKey); err != nil {
+		return
+	}
+	stanzas := make([]stanza, len(recipients))
+	for i, recipient := range recipients {
+		if stanzas[i], err = wrap(recipient, fileKey); err != nil {
+			return
+		}
+	}
+	err = writeStanzas(w, stanzas)
+	return
+}
+
+func Unwrap(r io.Reader, identities []kf.PrivateKey) (fileKey []byte, err error) {
+	stanzas, err := readStanzas(r)
+	if err != nil {
+		return
+	}
+	for _, identity := range identities {
+		var a kf.Algorithm
+		if a, err = kf.AlgorithmOf(identity); err != nil {
+			return
+		}
+		for _, s := range stanzas {
+			if !compatible(a, s.algorithm) {
+				continue
+			}
+			if fileKey, err = unwrap(identity, s); err == nil {
+				return
+			}
+		}
+	}
+	return nil, ErrIdentity
+}
+
+func compatible(identity, stanza kf.Algorithm) bool {
+	x25519 := func(a kf.Algorithm) bool { return a == kf.X25519 || a == kf.Ed25519 }
+	return identity == stanza || x25519(identity) && x25519(stanza)
+}
+
+func wrap(recipient kf.PublicKey, fileKey []byte) (s stanza, err error) {
+	if s.algorithm, err = kf.AlgorithmOf(recipient); err != nil {
+		return
+	}
+	var shared []byte
+	public := recipient.Bytes()
+	switch k := recipient.(type) {
+	case *sv.PublicKey:
+		var x *xp.PublicKey
+		if x, err = xp.NewEd25519PublicKey(k); err != nil {
+			return
+		}
+		if shared, s.share, err = exchange(x); err != nil {
+			return
+		}
+		public = x.Bytes()
+	case *xp.PublicKey:
+		if shared, s.share, err = exchange(k); err != nil {
+			return
+		}
+	case *xp.KEMPublicKey:
+		shared, s.share = k.Encapsulate()
+	default:
+		err = kf.ErrKey
+		return
+	}
+	aead, err := newAEAD(shared, s.share, public)
+	if err != nil {
+		return
+	}
+	s.wrapped = aead.Seal(nil, make([]byte, aead.NonceSize()), fileKey, nil)
+	return
+}
+
+func unwrap(identity kf.PrivateKey, s stanza) (fileKey []byte, err error) {
+	var (
+		shared []byte
+		public []byte
+	)
+	switch k := identity.(type) {
+	case *sv.PrivateKey:
+		var x *xp.PrivateKey
+		if x, err = xp.NewEd25519PrivateKey(k); err != nil {
+			return
+		}
+		var ephemeral *xp.PublicKey
+		if ephemeral, err = xp.NewPublicKey(xp.X25519, s.share); err != nil {
+			return
+		}
+		if shared, err = x.ECDH(ephemeral); err != nil {
+			return
+		}
+		public = x.PublicKey().Bytes()
+	case *xp.PrivateKey:
+		var ephemeral *xp.PublicKey
+		if ephemeral, err = xp.NewPublicKey(k.Curve(), s.share); err != nil {
+			return
+		}
+		if shared, err = k.ECDH(ephemeral); err != nil {
+			return
+		}
+		public = k.PublicKey().Bytes()
+	case *xp.KEMPrivateKey:
+		if shared, err = k.Decapsulate(s.share); err != nil {
+			return
+		}
+		public = k.PublicKey().Bytes()
+	default:
+		err = kf.ErrKey
+		return
+	}
+	aead, err := newAEAD(shared, s.share, public)
+	if err != nil {
+		return
+	}
+	return aead.Open(nil, make([]byte, aead.NonceSize()), s.wrapped, nil)
+}
+
+func exchange(recipient *xp.PublicKey) (shared, share []byte, err error) {
+	ephemeral, err := xp.GenerateKey(recipient.Curve())
+	if err != nil {
+		return
+	}
+	if shared, err = ephemeral.ECDH(recipient); err != nil {
+		return
+	}
+	share = ephemeral.PublicKey().Bytes()
+	return
+}
+
+func newAEAD(shared, share, public []byte) (cipher.AEAD, error) {
+	key, err := xp.K(geheim.SHA_256, shared, share, public, label, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
+
+func writeStanzas(w io.Writer, stanzas []stanza) error {
+	b := binary.BigEndian.AppendUint32(nil, Magic)
+	b = append(b, byte(len(stanzas)))
+	for _, s := range stanzas {
+		b = append(b, byte(s.algorithm))
+		b = binary.BigEndian.AppendUint16(b, uint16(len(s.share)))
+		b = append(b, s.share...)
+		b = append(b, s.wrapped...)
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readStanzas(r io.Reader) ([]stanza, error) {
+	var head struct {
+		Magic uint32
+		Count uint8
+	}
+	if err := binary.Read(r, binary.BigEndian, &head); err != nil {
+		return nil, err
+	}
+	if head.Magic != Magic || head.Count == 0 {
+		return nil, ErrHeader
+	}
+	stanzas := make([]stanza, head.Count)
+	for i := range stanzas {
+		var sh struct {
+			Algorithm uint8
+			Size      uint16
+		}
+		if err := binary.Read(r, binary.BigEndian, &sh); err != nil {
+			return nil, err
+		}
+		s := stanza{
+			algorithm: kf.Algorithm(sh.Algorithm),
+			share:     make([]byte, sh.Size),
+			wrapped:   make([]byte, wrappedSize),
+		}
+		if _, err := io.ReadFull(r, s.share); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, s.wrapped); err != nil {
+			return nil, err
+		}
+		stanzas[i] = s
+	}
+	return stanzas, nil
+}
@@ -0,0 +1,49 @@
+package rp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/rp"
+	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xp"
+)
+
+func TestEd25519X25519(t *testing.T) {
+	s, err := sv.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := xp.NewEd25519PrivateKey(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xpub, err := xp.NewEd25519PublicKey(s.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name      string
+		recipient kf.PublicKey
+		identity  kf.PrivateKey
+	}{
+		{"ed25519 to ed25519", s.PublicKey(), s},
+		{"ed25519 to x25519", s.PublicKey(), x},
+		{"x25519 to ed25519", xpub, s},
+		{"x25519 to x25519", xpub, x},
+	} {
+		var buf bytes.Buffer
+		fileKey, err := rp.Wrap(&buf, []kf.PublicKey{c.recipient})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		key, err := rp.Unwrap(&buf, []kf.PrivateKey{c.identity})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if !bytes.Equal(key, fileKey) {
+			t.Fatalf("%s: file key mismatch", c.name)
+		}
+	}
+}
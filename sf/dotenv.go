@@ -0,0 +1,129 @@
+package sf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var dotenvLine = regexp.MustCompile(`^(\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*=\s*)(.*)$`)
+
+const dotenvMetadataPrefix = MetadataKey + "_"
+
+func dotenvMetadata(name string) (string, bool) {
+	name, ok := strings.CutPrefix(name, dotenvMetadataPrefix)
+	return name, ok && (name == "version" || name == "key" || name == "mac")
+}
+
+type dotenvDocument struct{ lines []string }
+
+func isDotenv(data []byte) bool {
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimRight(line, "\r\n")
+		if t := strings.TrimSpace(line); t != "" && t[0] != '#' && !dotenvLine.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseDotenv(data []byte) (*dotenvDocument, error) {
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for _, line := range lines {
+		if t := strings.TrimSpace(line); t != "" && t[0] != '#' && !dotenvLine.MatchString(line) {
+			return nil, ErrDocument
+		}
+	}
+	return &dotenvDocument{lines}, nil
+}
+
+func (d *dotenvDocument) leaves() (leaves []*leaf, err error) {
+	for i, line := range d.lines {
+		m := dotenvLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if _, ok := dotenvMetadata(m[2]); ok {
+			continue
+		}
+		prefix := m[1]
+		leaves = append(leaves, &leaf{path: []string{m[2]}, typ: "str", value: m[3], set: func(_, value string) {
+			d.lines[i] = prefix + value
+		}})
+	}
+	return
+}
+
+func (d *dotenvDocument) metadata() (*metadata, error) {
+	var m *metadata
+	for _, line := range d.lines {
+		sm := dotenvLine.FindStringSubmatch(line)
+		if sm == nil {
+			continue
+		}
+		name, ok := dotenvMetadata(sm[2])
+		if !ok {
+			continue
+		}
+		if m == nil {
+			m = new(metadata)
+		}
+		switch name {
+		case "version":
+			version, err := strconv.Atoi(sm[3])
+			if err != nil {
+				return nil, ErrMetadata
+			}
+			m.Version = version
+		case "key":
+			m.Key = sm[3]
+		case "mac":
+			m.MAC = sm[3]
+		}
+	}
+	return m, nil
+}
+
+func (d *dotenvDocument) setMetadata(m *metadata) {
+	lines := d.lines[:0]
+	for _, line := range d.lines {
+		if sm := dotenvLine.FindStringSubmatch(line); sm == nil {
+			lines = append(lines, line)
+		} else if _, ok := dotenvMetadata(sm[2]); !ok {
+			lines = append(lines, line)
+		}
+	}
+	d.lines = lines
+	if m == nil {
+		return
+	}
+	d.lines = append(d.lines,
+		dotenvMetadataPrefix+"version="+strconv.Itoa(m.Version),
+		dotenvMetadataPrefix+"key="+m.Key,
+		dotenvMetadataPrefix+"mac="+m.MAC,
+	)
+}
+
+func (d *dotenvDocument) marshal() ([]byte, error) {
+	var b bytes.Buffer
+	for _, line := range d.lines {
+		b.WriteString(line + "\n")
+	}
+	return b.Bytes(), nil
+}
+
+func unquoteDotenv(s string) string {
+	s = strings.TrimSpace(s)
+	switch {
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1]
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		r := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`, `\$`, `$`)
+		return r.Replace(s[1 : len(s)-1])
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
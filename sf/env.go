@@ -0,0 +1,26 @@
+package sf
+
+import "strings"
+
+func Env(data []byte, format Format) ([]string, error) {
+	doc, err := parse(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if m, err := doc.metadata(); err != nil || m != nil {
+		return nil, ErrEncrypted
+	}
+	leaves, err := doc.leaves()
+	if err != nil {
+		return nil, err
+	}
+	env := make([]string, len(leaves))
+	for i, l := range leaves {
+		value := l.value
+		if format == Dotenv {
+			value = unquoteDotenv(value)
+		}
+		env[i] = strings.Join(l.path, "_") + "=" + value
+	}
+	return env, nil
+}
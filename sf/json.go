@@ -0,0 +1,269 @@
+package sf
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+type jsonNode struct {
+	delim rune
+	keys  []string
+	items []*jsonNode
+	value any
+	raw   []byte
+	lead  []byte
+	head  []byte
+	tail  []byte
+}
+
+type jsonDocument struct {
+	root           *jsonNode
+	prefix, suffix []byte
+}
+
+type jsonDecoder struct {
+	*json.Decoder
+	data []byte
+	end  int
+}
+
+func (d *jsonDecoder) token() (t json.Token, start int, err error) {
+	if t, err = d.Token(); err != nil {
+		return
+	}
+	rest := d.data[d.end:]
+	start = d.end + len(rest) - len(bytes.TrimLeft(rest, " \t\r\n,:"))
+	d.end = int(d.InputOffset())
+	return
+}
+
+func parseJSON(data []byte) (*jsonDocument, error) {
+	d := &jsonDecoder{Decoder: json.NewDecoder(bytes.NewReader(data)), data: data}
+	d.UseNumber()
+	root, start, err := decodeJSON(d)
+	if err != nil {
+		return nil, err
+	}
+	end := d.end
+	if _, err := d.Token(); err != io.EOF {
+		return nil, ErrDocument
+	}
+	if root.delim != '{' {
+		return nil, ErrDocument
+	}
+	return &jsonDocument{root, data[:start], data[end:]}, nil
+}
+
+func decodeJSON(d *jsonDecoder) (*jsonNode, int, error) {
+	t, start, err := d.token()
+	if err != nil {
+		return nil, 0, err
+	}
+	delim, ok := t.(json.Delim)
+	if !ok {
+		return &jsonNode{value: t, raw: d.data[start:d.end]}, start, nil
+	}
+	n := &jsonNode{delim: rune(delim)}
+	prev := d.end
+	for d.More() {
+		head := -1
+		if n.delim == '{' {
+			t, keyStart, err := d.token()
+			if err != nil {
+				return nil, 0, err
+			}
+			n.keys = append(n.keys, t.(string))
+			head = keyStart
+		}
+		item, itemStart, err := decodeJSON(d)
+		if err != nil {
+			return nil, 0, err
+		}
+		if head < 0 {
+			head = itemStart
+		}
+		item.lead, item.head = d.data[prev:head], d.data[head:itemStart]
+		n.items = append(n.items, item)
+		prev = d.end
+	}
+	if _, _, err := d.token(); err != nil {
+		return nil, 0, err
+	}
+	n.tail = d.data[prev : d.end-1]
+	return n, start, nil
+}
+
+func (d *jsonDocument) leaves() (leaves []*leaf, err error) {
+	var walk func(n *jsonNode, path []string)
+	walk = func(n *jsonNode, path []string) {
+		for i, item := range n.items {
+			p := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if n.delim == '{' {
+				if len(path) == 0 && n.keys[i] == MetadataKey {
+					continue
+				}
+				p[len(p)-1] = n.keys[i]
+			}
+			if item.delim != 0 {
+				walk(item, p)
+				continue
+			}
+			l := &leaf{path: p}
+			switch v := item.value.(type) {
+			case string:
+				l.typ, l.value = "str", v
+			case json.Number:
+				l.typ, l.value = "float", v.String()
+				if _, err := v.Int64(); err == nil {
+					l.typ = "int"
+				}
+			case bool:
+				l.typ, l.value = "bool", strconv.FormatBool(v)
+			default:
+				continue
+			}
+			l.set = func(typ, value string) {
+				item.raw = nil
+				switch typ {
+				case "int", "float":
+					item.value = json.Number(value)
+				case "bool":
+					item.value = value == "true"
+				default:
+					item.value = value
+				}
+			}
+			leaves = append(leaves, l)
+		}
+	}
+	walk(d.root, nil)
+	return
+}
+
+func (d *jsonDocument) metadata() (*metadata, error) {
+	for i, k := range d.root.keys {
+		if k != MetadataKey {
+			continue
+		}
+		n := d.root.items[i]
+		m := new(metadata)
+		for j, k := range n.keys {
+			v := n.items[j].value
+			switch k {
+			case "version":
+				if num, ok := v.(json.Number); ok {
+					version, err := num.Int64()
+					if err != nil {
+						return nil, ErrMetadata
+					}
+					m.Version = int(version)
+				}
+			case "key":
+				m.Key, _ = v.(string)
+			case "mac":
+				m.MAC, _ = v.(string)
+			}
+		}
+		return m, nil
+	}
+	return nil, nil
+}
+
+func (d *jsonDocument) setMetadata(m *metadata) {
+	for i, k := range d.root.keys {
+		if k == MetadataKey {
+			d.root.keys = append(d.root.keys[:i], d.root.keys[i+1:]...)
+			d.root.items = append(d.root.items[:i], d.root.items[i+1:]...)
+			break
+		}
+	}
+	if m == nil {
+		return
+	}
+	d.root.keys = append(d.root.keys, MetadataKey)
+	d.root.items = append(d.root.items, &jsonNode{
+		delim: '{',
+		keys:  []string{"version", "key", "mac"},
+		items: []*jsonNode{{value: json.Number(strconv.Itoa(m.Version))}, {value: m.Key}, {value: m.MAC}},
+	})
+}
+
+func (d *jsonDocument) marshal() ([]byte, error) {
+	var b bytes.Buffer
+	b.Write(d.prefix)
+	if err := encodeJSON(&b, d.root, "", false); err != nil {
+		return nil, err
+	}
+	b.Write(d.suffix)
+	return b.Bytes(), nil
+}
+
+func encodeJSON(b *bytes.Buffer, n *jsonNode, indent string, compact bool) error {
+	if n.delim == 0 {
+		if n.raw != nil {
+			b.Write(n.raw)
+			return nil
+		}
+		return writeJSON(b, n.value)
+	}
+	if len(n.items) > 0 && n.items[0].lead != nil {
+		compact = bytes.IndexByte(n.items[0].lead, '\n') < 0
+	} else if n.tail != nil {
+		compact = bytes.IndexByte(n.tail, '\n') < 0
+	}
+	b.WriteRune(n.delim)
+	for i, item := range n.items {
+		lead := item.lead
+		if lead == nil {
+			switch {
+			case i > 0 && n.items[i-1].lead != nil:
+				lead = n.items[i-1].lead
+			case compact:
+				lead = []byte(", ")
+			default:
+				lead = []byte(",\n" + indent + "  ")
+			}
+		}
+		switch comma := bytes.IndexByte(lead, ',') >= 0; {
+		case i == 0 && comma:
+			lead = bytes.TrimLeft(bytes.Replace(lead, []byte(","), nil, 1), " ")
+		case i > 0 && !comma:
+			b.WriteByte(',')
+		}
+		b.Write(lead)
+		if item.head != nil {
+			b.Write(item.head)
+		} else if n.delim == '{' {
+			if err := writeJSON(b, n.keys[i]); err != nil {
+				return err
+			}
+			b.WriteString(": ")
+		}
+		inner := indent + "  "
+		if j := bytes.LastIndexByte(lead, '\n'); j >= 0 {
+			inner = string(lead[j+1:])
+		}
+		if err := encodeJSON(b, item, inner, compact); err != nil {
+			return err
+		}
+	}
+	if n.tail != nil {
+		b.Write(n.tail)
+	} else if len(n.items) > 0 && !compact {
+		b.WriteString("\n" + indent)
+	}
+	b.WriteString(map[rune]string{'{': "}", '[': "]"}[n.delim])
+	return nil
+}
+
+func writeJSON(b *bytes.Buffer, v any) error {
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	b.Truncate(b.Len() - 1)
+	return nil
+}
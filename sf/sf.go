@@ -0,0 +1,294 @@
+package sf
+
+import (
+	"bytes"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jamesliu96/geheim"
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/rp"
+)
+
+type Format int
+
+const (
+	JSON Format = 1 + iota
+	YAML
+	Dotenv
+)
+
+var FormatNames = map[Format]string{
+	JSON:   "JSON",
+	YAML:   "YAML",
+	Dotenv: "Dotenv",
+}
+
+var formats = [...]Format{
+	JSON,
+	YAML,
+	Dotenv,
+}
+
+var FormatString = func() string {
+	d := make([]string, len(formats))
+	for i, f := range formats {
+		d[i] = fmt.Sprintf("%d:%s", f, FormatNames[f])
+	}
+	return strings.Join(d, ", ")
+}()
+
+const (
+	Version = 1
+
+	MetadataKey       = "geheim"
+	UnencryptedSuffix = "_unencrypted"
+
+	valuePrefix = "GHM["
+	valueSuffix = "]"
+	dataKeySize = 32
+	macLabel    = "geheim/sf/mac"
+)
+
+var (
+	ErrFormat    = fmt.Errorf("sf: invalid format (%s)", FormatString)
+	ErrDocument  = errors.New("sf: malformed document")
+	ErrEncrypted = errors.New("sf: document is already encrypted")
+	ErrMetadata  = errors.New("sf: missing or malformed metadata")
+	ErrValue     = errors.New("sf: malformed encrypted value")
+	ErrMAC       = errors.New("sf: document mac mismatch")
+)
+
+type metadata struct {
+	Version int
+	Key     string
+	MAC     string
+}
+
+type leaf struct {
+	path  []string
+	typ   string
+	value string
+	set   func(typ, value string)
+}
+
+type document interface {
+	leaves() ([]*leaf, error)
+	metadata() (*metadata, error)
+	setMetadata(*metadata)
+	marshal() ([]byte, error)
+}
+
+func DetectFormat(name string, data []byte) Format {
+	switch base := strings.ToLower(filepath.Base(name)); {
+	case strings.HasSuffix(base, ".json"):
+		return JSON
+	case strings.HasSuffix(base, ".yaml"), strings.HasSuffix(base, ".yml"):
+		return YAML
+	case strings.HasSuffix(base, ".env"), strings.HasPrefix(base, ".env"):
+		return Dotenv
+	}
+	t := bytes.TrimSpace(data)
+	if bytes.HasPrefix(t, []byte("{")) {
+		return JSON
+	}
+	if isDotenv(data) {
+		return Dotenv
+	}
+	return YAML
+}
+
+func parse(data []byte, format Format) (document, error) {
+	switch format {
+	case JSON:
+		return parseJSON(data)
+	case YAML:
+		return parseYAML(data)
+	case Dotenv:
+		return parseDotenv(data)
+	}
+	return nil, ErrFormat
+}
+
+func IsEncrypted(data []byte, format Format) bool {
+	doc, err := parse(data, format)
+	if err != nil {
+		return false
+	}
+	m, err := doc.metadata()
+	return err == nil && m != nil
+}
+
+func Encrypt(data []byte, format Format, passphrase []byte, recipients []kf.PublicKey) ([]byte, error) {
+	doc, err := parse(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if m, err := doc.metadata(); err != nil || m != nil {
+		return nil, ErrEncrypted
+	}
+	leaves, err := doc.leaves()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		dataKey []byte
+		wrapped bytes.Buffer
+	)
+	if len(recipients) > 0 {
+		if dataKey, err = rp.Wrap(&wrapped, recipients); err != nil {
+			return nil, err
+		}
+	} else {
+		dataKey = make([]byte, dataKeySize)
+		if _, err := rand.Read(dataKey); err != nil {
+			return nil, err
+		}
+		if _, err := geheim.EncryptArchive(bytes.NewReader(dataKey), &wrapped, passphrase, dataKeySize, geheim.DefaultCipher, geheim.DefaultHash, geheim.DefaultKDF, geheim.DefaultSec, nil); err != nil {
+			return nil, err
+		}
+	}
+	mac, err := computeMAC(dataKey, leaves)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range leaves {
+		if encrypted(l.path) {
+			value, err := encryptValue(dataKey, l.typ, l.value)
+			if err != nil {
+				return nil, err
+			}
+			l.set("str", value)
+		}
+	}
+	doc.setMetadata(&metadata{Version, base64.StdEncoding.EncodeToString(wrapped.Bytes()), hex.EncodeToString(mac)})
+	return doc.marshal()
+}
+
+func Decrypt(data []byte, format Format, passphrase []byte, identities []kf.PrivateKey) ([]byte, error) {
+	doc, err := parse(data, format)
+	if err != nil {
+		return nil, err
+	}
+	m, err := doc.metadata()
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || m.Version != Version {
+		return nil, ErrMetadata
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(m.Key)
+	if err != nil || len(wrapped) < 4 {
+		return nil, ErrMetadata
+	}
+	var dataKey []byte
+	if binary.BigEndian.Uint32(wrapped) == rp.Magic {
+		if dataKey, err = rp.Unwrap(bytes.NewReader(wrapped), identities); err != nil {
+			return nil, err
+		}
+	} else {
+		var b bytes.Buffer
+		if _, _, err := geheim.DecryptArchive(bytes.NewReader(wrapped), &b, passphrase, nil); err != nil {
+			return nil, err
+		}
+		dataKey = b.Bytes()
+	}
+	leaves, err := doc.leaves()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range leaves {
+		if !encrypted(l.path) {
+			continue
+		}
+		if l.typ, l.value, err = decryptValue(dataKey, l.value); err != nil {
+			return nil, err
+		}
+		l.set(l.typ, l.value)
+	}
+	mac, err := computeMAC(dataKey, leaves)
+	if err != nil {
+		return nil, err
+	}
+	if expected, err := hex.DecodeString(m.MAC); err != nil || !hmac.Equal(mac, expected) {
+		return nil, ErrMAC
+	}
+	doc.setMetadata(nil)
+	return doc.marshal()
+}
+
+func encrypted(path []string) bool {
+	for _, p := range path {
+		if strings.HasSuffix(p, UnencryptedSuffix) {
+			return false
+		}
+	}
+	return true
+}
+
+func encryptValue(key []byte, typ, value string) (string, error) {
+	var b bytes.Buffer
+	if _, err := geheim.EncryptArchive(strings.NewReader(value), &b, key, int64(len(value)), geheim.DefaultCipher, geheim.DefaultHash, geheim.HKDF, geheim.DefaultSec, nil); err != nil {
+		return "", err
+	}
+	return valuePrefix + typ + ":" + base64.StdEncoding.EncodeToString(b.Bytes()) + valueSuffix, nil
+}
+
+func decryptValue(key []byte, value string) (typ, plaintext string, err error) {
+	s, ok := strings.CutPrefix(value, valuePrefix)
+	if !ok {
+		err = ErrValue
+		return
+	}
+	if s, ok = strings.CutSuffix(s, valueSuffix); !ok {
+		err = ErrValue
+		return
+	}
+	typ, s, ok = strings.Cut(s, ":")
+	if !ok {
+		err = ErrValue
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		err = ErrValue
+		return
+	}
+	var b bytes.Buffer
+	if _, _, err = geheim.DecryptArchive(bytes.NewReader(data), &b, key, nil); err != nil {
+		return
+	}
+	plaintext = b.String()
+	return
+}
+
+func computeMAC(dataKey []byte, leaves []*leaf) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, dataKey, nil, macLabel, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, key)
+	write := func(s string) {
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(s))))
+		h.Write([]byte(s))
+	}
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(leaves))))
+	for _, l := range leaves {
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(l.path))))
+		for _, p := range l.path {
+			write(p)
+		}
+		write(l.typ)
+		write(l.value)
+	}
+	return h.Sum(nil), nil
+}
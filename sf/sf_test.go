@@ -0,0 +1,134 @@
+package sf_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jamesliu96/geheim/kf"
+	"github.com/jamesliu96/geheim/sf"
+	"github.com/jamesliu96/geheim/xp"
+)
+
+var documents = []struct {
+	name string
+	data string
+	env  []string
+}{
+	{
+		"secrets.json",
+		`{
+  "db": {
+    "user": "admin",
+    "password": "hunter2",
+    "port": 5432
+  },
+  "debug_unencrypted": true,
+  "tags": ["a", "b"]
+}
+`,
+		[]string{"db_user=admin", "db_password=hunter2", "db_port=5432", "debug_unencrypted=true", "tags_0=a", "tags_1=b"},
+	},
+	{
+		"secrets.yaml",
+		`# comment
+db:
+  user: admin
+  password: hunter2 # inline
+  port: 5432
+debug_unencrypted: true
+tags:
+  - a
+  - b
+`,
+		[]string{"db_user=admin", "db_password=hunter2", "db_port=5432", "debug_unencrypted=true", "tags_0=a", "tags_1=b"},
+	},
+	{
+		".env",
+		`# comment
+DB_USER=admin
+export DB_PASSWORD="hunter2"
+
+DEBUG_unencrypted=true
+`,
+		[]string{"DB_USER=admin", "DB_PASSWORD=hunter2", "DEBUG_unencrypted=true"},
+	},
+}
+
+func newKey(t *testing.T) (*xp.PrivateKey, []kf.PublicKey) {
+	key, err := xp.GenerateKey(xp.X25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, []kf.PublicKey{key.PublicKey()}
+}
+
+func TestRoundTrip(t *testing.T) {
+	key, recipients := newKey(t)
+	for _, d := range documents {
+		format := sf.DetectFormat(d.name, []byte(d.data))
+		if sf.DetectFormat("", []byte(d.data)) != format {
+			t.Fatalf("%s: format detection mismatch", d.name)
+		}
+		env, err := sf.Env([]byte(d.data), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(env)
+		want := slices.Sorted(slices.Values(d.env))
+		if !slices.Equal(env, want) {
+			t.Fatalf("%s: env %q, want %q", d.name, env, want)
+		}
+		ciphertext, err := sf.Encrypt([]byte(d.data), format, nil, recipients)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sf.IsEncrypted(ciphertext, format) {
+			t.Fatalf("%s: not encrypted", d.name)
+		}
+		if bytes.Contains(ciphertext, []byte("hunter2")) || !bytes.Contains(ciphertext, []byte("true")) {
+			t.Fatalf("%s: unexpected ciphertext\n%s", d.name, ciphertext)
+		}
+		if _, err := sf.Encrypt(ciphertext, format, nil, recipients); !errors.Is(err, sf.ErrEncrypted) {
+			t.Fatalf("%s: %v, want %v", d.name, err, sf.ErrEncrypted)
+		}
+		if _, err := sf.Env(ciphertext, format); !errors.Is(err, sf.ErrEncrypted) {
+			t.Fatalf("%s: %v, want %v", d.name, err, sf.ErrEncrypted)
+		}
+		plaintext, err := sf.Decrypt(ciphertext, format, nil, []kf.PrivateKey{key})
+		if err != nil {
+			t.Fatalf("%s: %v", d.name, err)
+		}
+		if string(plaintext) != d.data {
+			t.Fatalf("%s: plaintext\n%s\nwant\n%s", d.name, plaintext, d.data)
+		}
+	}
+}
+
+func TestSwap(t *testing.T) {
+	key, recipients := newKey(t)
+	for _, d := range documents {
+		format := sf.DetectFormat(d.name, nil)
+		ciphertext, err := sf.Encrypt([]byte(d.data), format, nil, recipients)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var values []string
+		for _, s := range strings.Split(string(ciphertext), "GHM[")[1:] {
+			values = append(values, "GHM["+s[:strings.Index(s, "]")+1])
+		}
+		if len(values) < 2 {
+			t.Fatalf("%s: %d encrypted values", d.name, len(values))
+		}
+		swapped := strings.NewReplacer(values[0], values[1], values[1], values[0]).Replace(string(ciphertext))
+		if _, err := sf.Decrypt([]byte(swapped), format, nil, []kf.PrivateKey{key}); !errors.Is(err, sf.ErrMAC) {
+			t.Fatalf("%s: %v, want %v", d.name, err, sf.ErrMAC)
+		}
+		flipped := strings.Replace(string(ciphertext), "true", "false", 1)
+		if _, err := sf.Decrypt([]byte(flipped), format, nil, []kf.PrivateKey{key}); !errors.Is(err, sf.ErrMAC) {
+			t.Fatalf("%s: %v, want %v", d.name, err, sf.ErrMAC)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package sf
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type yamlDocument struct{ root *yaml.Node }
+
+func parseYAML(data []byte) (*yamlDocument, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, ErrDocument
+	}
+	return &yamlDocument{&doc}, nil
+}
+
+func (d *yamlDocument) mapping() *yaml.Node { return d.root.Content[0] }
+
+func (d *yamlDocument) leaves() (leaves []*leaf, err error) {
+	var walk func(n *yaml.Node, path []string)
+	walk = func(n *yaml.Node, path []string) {
+		step := 1
+		if n.Kind == yaml.MappingNode {
+			step = 2
+		}
+		for i := 0; i < len(n.Content); i += step {
+			item := n.Content[i]
+			p := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if step == 2 {
+				if len(path) == 0 && item.Value == MetadataKey {
+					continue
+				}
+				p[len(p)-1] = item.Value
+				item = n.Content[i+1]
+			}
+			switch item.Kind {
+			case yaml.MappingNode, yaml.SequenceNode:
+				walk(item, p)
+				continue
+			case yaml.ScalarNode:
+			default:
+				continue
+			}
+			typ := strings.TrimPrefix(item.ShortTag(), "!!")
+			switch typ {
+			case "str", "int", "float", "bool":
+			default:
+				continue
+			}
+			leaves = append(leaves, &leaf{path: p, typ: typ, value: item.Value, set: func(typ, value string) {
+				item.Tag, item.Value = "!!"+typ, value
+				if typ == "str" && item.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 && !strings.Contains(value, "\n") {
+					item.Style = 0
+				}
+			}})
+		}
+	}
+	walk(d.mapping(), nil)
+	return
+}
+
+func (d *yamlDocument) metadata() (*metadata, error) {
+	n := d.mapping()
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value != MetadataKey {
+			continue
+		}
+		var m struct {
+			Version int    `yaml:"version"`
+			Key     string `yaml:"key"`
+			MAC     string `yaml:"mac"`
+		}
+		if err := n.Content[i+1].Decode(&m); err != nil {
+			return nil, ErrMetadata
+		}
+		return &metadata{m.Version, m.Key, m.MAC}, nil
+	}
+	return nil, nil
+}
+
+func (d *yamlDocument) setMetadata(m *metadata) {
+	n := d.mapping()
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == MetadataKey {
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			break
+		}
+	}
+	if m == nil {
+		return
+	}
+	scalar := func(tag, value string) *yaml.Node { return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value} }
+	n.Content = append(n.Content, scalar("!!str", MetadataKey), &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		scalar("!!str", "version"), scalar("!!int", strconv.Itoa(m.Version)),
+		scalar("!!str", "key"), scalar("!!str", m.Key),
+		scalar("!!str", "mac"), scalar("!!str", m.MAC),
+	}})
+}
+
+func (d *yamlDocument) marshal() ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(d.root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,19 @@
+package ss
+
+func gfMul(a, b byte) (p byte) {
+	for range 8 {
+		p ^= -(b & 1) & a
+		a = a<<1 ^ -(a>>7)&0x1b
+		b >>= 1
+	}
+	return
+}
+
+func gfInv(a byte) byte {
+	b := a
+	for range 6 {
+		b = gfMul(b, b)
+		b = gfMul(b, a)
+	}
+	return gfMul(b, b)
+}
@@ -0,0 +1,178 @@
+package ss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	IDSize       = 4
+	checksumSize = 4
+	digestSize   = 16
+	headerSize   = IDSize + 2
+)
+
+const (
+	MinThreshold = 2
+	MaxShares    = 255
+)
+
+var (
+	ErrThreshold = fmt.Errorf("ss: invalid threshold (%d <= threshold <= shares <= %d)", MinThreshold, MaxShares)
+	ErrSecret    = errors.New("ss: empty secret")
+	ErrShare     = errors.New("ss: malformed share")
+	ErrChecksum  = errors.New("ss: share checksum mismatch")
+	ErrMismatch  = errors.New("ss: shares do not belong together")
+	ErrShares    = errors.New("ss: not enough shares")
+	ErrDigest    = errors.New("ss: recovered secret digest mismatch")
+)
+
+type Share struct {
+	ID        [IDSize]byte
+	Threshold uint8
+	Index     uint8
+	Value     []byte
+}
+
+func Split(secret []byte, threshold, shares int) ([]Share, error) {
+	if len(secret) == 0 {
+		return nil, ErrSecret
+	}
+	if threshold < MinThreshold || threshold > shares || shares > MaxShares {
+		return nil, ErrThreshold
+	}
+	var id [IDSize]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	value := append(bytes.Clone(secret), digest(id, secret)...)
+	coefficients := make([]byte, (threshold-1)*len(value))
+	if _, err := rand.Read(coefficients); err != nil {
+		return nil, err
+	}
+	d := make([]Share, shares)
+	for i := range d {
+		x := byte(i + 1)
+		y := make([]byte, len(value))
+		for j, v := range value {
+			var acc byte
+			for k := threshold - 2; k >= 0; k-- {
+				acc = gfMul(acc, x) ^ coefficients[k*len(value)+j]
+			}
+			y[j] = gfMul(acc, x) ^ v
+		}
+		d[i] = Share{id, uint8(threshold), x, y}
+	}
+	return d, nil
+}
+
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrShares
+	}
+	first := shares[0]
+	var unique []Share
+	for _, s := range shares {
+		if s.ID != first.ID || s.Threshold != first.Threshold || len(s.Value) != len(first.Value) || s.Index == 0 {
+			return nil, ErrMismatch
+		}
+		if !containsIndex(unique, s.Index) {
+			unique = append(unique, s)
+		}
+	}
+	if len(unique) < int(first.Threshold) {
+		return nil, ErrShares
+	}
+	unique = unique[:first.Threshold]
+	value := make([]byte, len(first.Value))
+	for i, si := range unique {
+		basis := byte(1)
+		for j, sj := range unique {
+			if i != j {
+				basis = gfMul(basis, gfMul(sj.Index, gfInv(sj.Index^si.Index)))
+			}
+		}
+		for k := range value {
+			value[k] ^= gfMul(basis, si.Value[k])
+		}
+	}
+	if len(value) <= digestSize {
+		return nil, ErrShare
+	}
+	secret, d := value[:len(value)-digestSize], value[len(value)-digestSize:]
+	if subtle.ConstantTimeCompare(d, digest(first.ID, secret)) != 1 {
+		return nil, ErrDigest
+	}
+	return secret, nil
+}
+
+func (s Share) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, headerSize+len(s.Value)+checksumSize)
+	b = append(b, s.ID[:]...)
+	b = append(b, s.Threshold, s.Index)
+	b = append(b, s.Value...)
+	return append(b, checksum(b)...), nil
+}
+
+func (s *Share) UnmarshalBinary(b []byte) error {
+	if len(b) <= headerSize+checksumSize {
+		return ErrShare
+	}
+	data, sum := b[:len(b)-checksumSize], b[len(b)-checksumSize:]
+	if subtle.ConstantTimeCompare(sum, checksum(data)) != 1 {
+		return ErrChecksum
+	}
+	copy(s.ID[:], data)
+	s.Threshold = data[IDSize]
+	s.Index = data[IDSize+1]
+	s.Value = bytes.Clone(data[headerSize:])
+	return nil
+}
+
+func (s Share) MarshalText() ([]byte, error) {
+	b, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+func (s *Share) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimSpace(string(text)))
+	if err != nil {
+		return ErrShare
+	}
+	return s.UnmarshalBinary(b)
+}
+
+func (s Share) String() string {
+	text, _ := s.MarshalText()
+	return string(text)
+}
+
+func digest(id [IDSize]byte, secret []byte) []byte {
+	h := sha256.New()
+	h.Write(id[:])
+	h.Write(secret)
+	return h.Sum(nil)[:digestSize]
+}
+
+func checksum(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:checksumSize]
+}
+
+func containsIndex(shares []Share, index uint8) bool {
+	for _, s := range shares {
+		if s.Index == index {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package ss_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/jamesliu96/geheim/ss"
+)
+
+func subsets(n, k int, f func([]int)) {
+	var walk func(start int, set []int)
+	walk = func(start int, set []int) {
+		if len(set) == k {
+			f(set)
+			return
+		}
+		for i := start; i < n; i++ {
+			walk(i+1, append(set, i))
+		}
+	}
+	walk(0, nil)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, secret := range [][]byte{
+		{0},
+		[]byte("secret\n"),
+		[]byte("secret\r\n"),
+		bytes.Repeat([]byte{0xff}, 100),
+		[]byte(rand.Text()),
+	} {
+		for _, c := range []struct{ threshold, shares int }{{2, 2}, {2, 3}, {3, 5}, {5, 5}, {4, 7}} {
+			shares, err := ss.Split(secret, c.threshold, c.shares)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k := c.threshold; k <= c.shares; k++ {
+				subsets(c.shares, k, func(set []int) {
+					subset := make([]ss.Share, len(set))
+					for i, j := range set {
+						subset[i] = shares[j]
+					}
+					got, err := ss.Combine(subset)
+					if err != nil {
+						t.Fatalf("%d/%d %v: %v", c.threshold, c.shares, set, err)
+					}
+					if !bytes.Equal(got, secret) {
+						t.Fatalf("%d/%d %v: secret %q, want %q", c.threshold, c.shares, set, got, secret)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestMaxShares(t *testing.T) {
+	secret := []byte("secret")
+	shares, err := ss.Split(secret, ss.MaxShares, ss.MaxShares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ss.Combine(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatalf("secret %q, want %q", got, secret)
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	shares, err := ss.Split([]byte("secret"), 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, subset := range [][]ss.Share{
+		nil,
+		shares[:1],
+		shares[:2],
+		{shares[0], shares[1], shares[1]},
+		{shares[4], shares[4], shares[4], shares[4]},
+	} {
+		if _, err := ss.Combine(subset); !errors.Is(err, ss.ErrShares) {
+			t.Errorf("%d shares: %v, want %v", len(subset), err, ss.ErrShares)
+		}
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	for _, c := range []struct{ threshold, shares int }{{0, 3}, {1, 3}, {4, 3}, {2, ss.MaxShares + 1}} {
+		if _, err := ss.Split([]byte("secret"), c.threshold, c.shares); !errors.Is(err, ss.ErrThreshold) {
+			t.Errorf("%d/%d: %v, want %v", c.threshold, c.shares, err, ss.ErrThreshold)
+		}
+	}
+	if _, err := ss.Split(nil, 2, 3); !errors.Is(err, ss.ErrSecret) {
+		t.Errorf("%v, want %v", err, ss.ErrSecret)
+	}
+}
+
+func TestMismatch(t *testing.T) {
+	a, err := ss.Split([]byte("secret"), 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ss.Split([]byte("secret"), 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ss.Combine([]ss.Share{a[0], b[1]}); !errors.Is(err, ss.ErrMismatch) {
+		t.Fatalf("%v, want %v", err, ss.ErrMismatch)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	shares, err := ss.Split([]byte("secret"), 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares[0].Value[0] ^= 1
+	if _, err := ss.Combine(shares[:2]); !errors.Is(err, ss.ErrDigest) {
+		t.Fatalf("%v, want %v", err, ss.ErrDigest)
+	}
+}
+
+func TestText(t *testing.T) {
+	shares, err := ss.Split([]byte("secret"), 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := shares[0].MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var share ss.Share
+	if err := share.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if share.ID != shares[0].ID || share.Threshold != shares[0].Threshold || share.Index != shares[0].Index || !bytes.Equal(share.Value, shares[0].Value) {
+		t.Fatalf("share %v, want %v", share, shares[0])
+	}
+	text[len(text)/2] ^= 1
+	if err := share.UnmarshalText(text); err == nil {
+		t.Fatal("expected error")
+	}
+	text[len(text)/2] ^= 1
+	if text[len(text)/2] == '0' {
+		text[len(text)/2] = '1'
+	} else {
+		text[len(text)/2] = '0'
+	}
+	if err := share.UnmarshalText(text); !errors.Is(err, ss.ErrChecksum) {
+		t.Fatalf("%v, want %v", err, ss.ErrChecksum)
+	}
+	if err := share.UnmarshalText([]byte("zz")); !errors.Is(err, ss.ErrShare) {
+		t.Fatalf("%v, want %v", err, ss.ErrShare)
+	}
+}
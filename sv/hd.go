@@ -0,0 +1,11 @@
+package sv
+
+import "github.com/jamesliu96/geheim/hd"
+
+func Derive(seed []byte, path string) (*PrivateKey, error) {
+	k, err := hd.Derive(hd.Ed25519, seed, path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyFromSeed(k.Key)
+}
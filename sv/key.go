@@ -0,0 +1,87 @@
+package sv
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+const SeedSize = ed25519.SeedSize
+
+type PrivateKey struct{ key ed25519.PrivateKey }
+
+type PublicKey struct{ key ed25519.PublicKey }
+
+var _ crypto.Signer = (*PrivateKey)(nil)
+
+var (
+	ErrPrivateKey = errors.New("sv: invalid private key")
+	ErrPublicKey  = errors.New("sv: invalid public key")
+)
+
+func GenerateKey() (*PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{key}, nil
+}
+
+func NewKeyFromSeed(seed []byte) (*PrivateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, ErrPrivateKey
+	}
+	return &PrivateKey{ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+func NewPrivateKey(key []byte) (*PrivateKey, error) {
+	if len(key) != PrivateSize {
+		return nil, ErrPrivateKey
+	}
+	k, err := NewKeyFromSeed(key[:SeedSize])
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(k.key[SeedSize:], key[SeedSize:]) {
+		return nil, ErrPrivateKey
+	}
+	return k, nil
+}
+
+func NewPublicKey(key []byte) (*PublicKey, error) {
+	if len(key) != PublicSize {
+		return nil, ErrPublicKey
+	}
+	return &PublicKey{bytes.Clone(key)}, nil
+}
+
+func (k *PrivateKey) Bytes() []byte { return bytes.Clone(k.key) }
+
+func (k *PrivateKey) Seed() []byte { return k.key.Seed() }
+
+func (k *PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(*PrivateKey)
+	return ok && subtle.ConstantTimeCompare(k.key, xx.key) == 1
+}
+
+func (k *PrivateKey) Public() crypto.PublicKey { return k.key.Public() }
+
+func (k *PrivateKey) PublicKey() *PublicKey { return &PublicKey{k.key.Public().(ed25519.PublicKey)} }
+
+func (k *PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	return k.key.Sign(rand, message, opts)
+}
+
+func (k *PublicKey) Bytes() []byte { return bytes.Clone(k.key) }
+
+func (k *PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*PublicKey)
+	return ok && k.key.Equal(xx.key)
+}
+
+func (k *PublicKey) Verify(message, signature []byte) error {
+	return ed25519.VerifyWithOptions(k.key, message, signature, &ed25519.Options{})
+}
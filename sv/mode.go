@@ -0,0 +1,102 @@
+package sv
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Mode int
+
+const (
+	Pure Mode = 1 + iota
+	Context
+	Prehash
+)
+
+var ModeNames = map[Mode]string{
+	Pure:    "Ed25519",
+	Context: "Ed25519ctx",
+	Prehash: "Ed25519ph",
+}
+
+var modes = [...]Mode{
+	Pure,
+	Context,
+	Prehash,
+}
+
+var ModeString = func() string {
+	d := make([]string, len(modes))
+	for i, m := range modes {
+		d[i] = fmt.Sprintf("%d:%s", m, ModeNames[m])
+	}
+	return strings.Join(d, ", ")
+}()
+
+const MaxContextSize = 255
+
+var (
+	ErrMode    = fmt.Errorf("sv: invalid mode (%s)", ModeString)
+	ErrContext = errors.New("sv: invalid context")
+)
+
+func options(mode Mode, context string) (*ed25519.Options, error) {
+	if len(context) > MaxContextSize {
+		return nil, ErrContext
+	}
+	switch mode {
+	case Pure:
+		if context != "" {
+			return nil, ErrContext
+		}
+		return &ed25519.Options{}, nil
+	case Context:
+		if context == "" {
+			return nil, ErrContext
+		}
+		return &ed25519.Options{Context: context}, nil
+	case Prehash:
+		return &ed25519.Options{Hash: crypto.SHA512, Context: context}, nil
+	}
+	return nil, ErrMode
+}
+
+func message(mode Mode, r io.Reader) ([]byte, error) {
+	if mode != Prehash {
+		return io.ReadAll(r)
+	}
+	h := sha512.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func (k *PrivateKey) SignMode(mode Mode, r io.Reader, context string) (signature []byte, err error) {
+	opts, err := options(mode, context)
+	if err != nil {
+		return
+	}
+	m, err := message(mode, r)
+	if err != nil {
+		return
+	}
+	return k.key.Sign(nil, m, opts)
+}
+
+func (k *PublicKey) VerifyMode(mode Mode, r io.Reader, signature []byte, context string) error {
+	opts, err := options(mode, context)
+	if err != nil {
+		return err
+	}
+	m, err := message(mode, r)
+	if err != nil {
+		return err
+	}
+	return ed25519.VerifyWithOptions(k.key, m, signature, opts)
+}
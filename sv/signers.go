@@ -0,0 +1,206 @@
+package sv
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var ErrAllowedSigners = errors.New("sv: malformed allowed signers")
+
+type AllowedSigner struct {
+	Principals    string
+	Namespaces    string
+	CertAuthority bool
+	ValidAfter    time.Time
+	ValidBefore   time.Time
+	PublicKey     *PublicKey
+}
+
+func ParseAllowedSigners(data []byte) (signers []*AllowedSigner, err error) {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var a *AllowedSigner
+		if a, err = parseAllowedSigner(line); err != nil {
+			return
+		}
+		if a != nil {
+			signers = append(signers, a)
+		}
+	}
+	err = s.Err()
+	return
+}
+
+func parseAllowedSigner(line string) (*AllowedSigner, error) {
+	fields := splitQuoted(line, func(c rune) bool { return c == ' ' || c == '\t' })
+	if len(fields) < 3 {
+		return nil, ErrAllowedSigners
+	}
+	a := &AllowedSigner{Principals: unquote(fields[0])}
+	fields = fields[1:]
+	if !isSSHKeyType(fields[0]) {
+		for _, option := range splitQuoted(fields[0], func(c rune) bool { return c == ',' }) {
+			name, value, _ := strings.Cut(option, "=")
+			value = unquote(value)
+			var err error
+			switch strings.ToLower(name) {
+			case "cert-authority":
+				a.CertAuthority = true
+			case "namespaces":
+				a.Namespaces = value
+			case "valid-after":
+				a.ValidAfter, err = parseSignerTime(value)
+			case "valid-before":
+				a.ValidBefore, err = parseSignerTime(value)
+			default:
+				err = ErrAllowedSigners
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		return nil, ErrAllowedSigners
+	}
+	if fields[0] != ssh.KeyAlgoED25519 {
+		return nil, nil
+	}
+	public, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.Join(fields, " ")))
+	if err != nil {
+		return nil, err
+	}
+	if a.PublicKey, err = NewPublicKey(public.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey)); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *AllowedSigner) Allows(principal, namespace string, t time.Time) bool {
+	return matchList(principal, a.Principals) && a.valid(namespace, t)
+}
+
+func (a *AllowedSigner) valid(namespace string, t time.Time) bool {
+	return !a.CertAuthority &&
+		(a.Namespaces == "" || matchList(namespace, a.Namespaces)) &&
+		(a.ValidAfter.IsZero() || !t.Before(a.ValidAfter)) &&
+		(a.ValidBefore.IsZero() || t.Before(a.ValidBefore))
+}
+
+func FindPrincipals(signers []*AllowedSigner, key *PublicKey, namespace string, t time.Time) (principals []string) {
+	for _, a := range signers {
+		if a.PublicKey.Equal(key) && a.valid(namespace, t) {
+			principals = append(principals, strings.Split(a.Principals, ",")...)
+		}
+	}
+	return
+}
+
+func (s *SSHSignature) VerifyAllowed(signers []*AllowedSigner, principal string, t time.Time) error {
+	for _, a := range signers {
+		if a.PublicKey.Equal(s.PublicKey) && a.Allows(principal, s.Namespace, t) {
+			return nil
+		}
+	}
+	return ErrPublicKey
+}
+
+func isSSHKeyType(s string) bool {
+	return strings.HasPrefix(s, "ssh-") || strings.HasPrefix(s, "ecdsa-") || strings.HasPrefix(s, "sk-")
+}
+
+func parseSignerTime(s string) (t time.Time, err error) {
+	loc := time.Local
+	if u, ok := strings.CutSuffix(s, "Z"); ok {
+		s, loc = u, time.UTC
+	}
+	layout := map[int]string{8: "20060102", 12: "200601021504", 14: "20060102150405"}[len(s)]
+	if layout == "" {
+		err = ErrAllowedSigners
+		return
+	}
+	return time.ParseInLocation(layout, s, loc)
+}
+
+func splitQuoted(s string, sep func(rune) bool) (fields []string) {
+	quoted := false
+	start := -1
+	for i, c := range s {
+		if c == '"' {
+			quoted = !quoted
+		}
+		if !quoted && sep(c) {
+			if start >= 0 {
+				fields = append(fields, s[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, s[start:])
+	}
+	return
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func matchList(s, patterns string) bool {
+	matched := false
+	for _, p := range strings.Split(patterns, ",") {
+		negated := strings.HasPrefix(p, "!")
+		if negated {
+			p = p[1:]
+		}
+		if matchPattern(s, p) {
+			if negated {
+				return false
+			}
+			matched = true
+		}
+	}
+	return matched
+}
+
+func matchPattern(s, p string) bool {
+	for len(p) > 0 {
+		switch p[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if matchPattern(s[i:], p[1:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+		default:
+			if len(s) == 0 || s[0] != p[0] {
+				return false
+			}
+		}
+		s, p = s[1:], p[1:]
+	}
+	return len(s) == 0
+}
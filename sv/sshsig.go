@@ -0,0 +1,188 @@
+package sv
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	SSHSigMagic   = "SSHSIG"
+	SSHSigVersion = 1
+
+	TypeSSHSignature = "SSH SIGNATURE"
+
+	HashSHA256 = "sha256"
+	HashSHA512 = "sha512"
+
+	DefaultSSHSigHash = HashSHA512
+
+	armorBegin   = "-----BEGIN " + TypeSSHSignature + "-----"
+	armorEnd     = "-----END " + TypeSSHSignature + "-----"
+	armorColumns = 70
+)
+
+var (
+	ErrSSHSig    = errors.New("sv: malformed ssh signature")
+	ErrNamespace = errors.New("sv: invalid namespace")
+	ErrHash      = errors.New("sv: unsupported hash algorithm")
+)
+
+type SSHSignature struct {
+	PublicKey *PublicKey
+	Namespace string
+	Hash      string
+	Signature []byte
+}
+
+type sshSigBlob struct {
+	Version   uint32
+	PublicKey []byte
+	Namespace string
+	Reserved  string
+	Hash      string
+	Signature []byte
+}
+
+func sshSigHash(name string) (func() hash.Hash, error) {
+	switch name {
+	case HashSHA256:
+		return sha256.New, nil
+	case HashSHA512:
+		return sha512.New, nil
+	}
+	return nil, ErrHash
+}
+
+func sshSigData(r io.Reader, namespace, hashName string) ([]byte, error) {
+	if namespace == "" {
+		return nil, ErrNamespace
+	}
+	h, err := sshSigHash(hashName)
+	if err != nil {
+		return nil, err
+	}
+	hh := h()
+	if _, err := io.Copy(hh, r); err != nil {
+		return nil, err
+	}
+	return append([]byte(SSHSigMagic), ssh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		Hash      string
+		Digest    []byte
+	}{namespace, "", hashName, hh.Sum(nil)})...), nil
+}
+
+func (k *PrivateKey) SignSSH(r io.Reader, namespace, hash string) (*SSHSignature, error) {
+	data, err := sshSigData(r, namespace, hash)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.NewSignerFromSigner(k)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(nil, data)
+	if err != nil {
+		return nil, err
+	}
+	return &SSHSignature{k.PublicKey(), namespace, hash, sig.Blob}, nil
+}
+
+func (s *SSHSignature) Verify(r io.Reader, namespace string) error {
+	if s.Namespace != namespace {
+		return ErrNamespace
+	}
+	data, err := sshSigData(r, namespace, s.Hash)
+	if err != nil {
+		return err
+	}
+	return s.PublicKey.Verify(data, s.Signature)
+}
+
+func (s *SSHSignature) MarshalBinary() ([]byte, error) {
+	public, err := ssh.NewPublicKey(s.PublicKey.key)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(SSHSigMagic), ssh.Marshal(sshSigBlob{
+		Version:   SSHSigVersion,
+		PublicKey: public.Marshal(),
+		Namespace: s.Namespace,
+		Hash:      s.Hash,
+		Signature: ssh.Marshal(ssh.Signature{Format: ssh.KeyAlgoED25519, Blob: s.Signature}),
+	})...), nil
+}
+
+func (s *SSHSignature) UnmarshalBinary(data []byte) error {
+	rest, ok := bytes.CutPrefix(data, []byte(SSHSigMagic))
+	if !ok {
+		return ErrSSHSig
+	}
+	var blob sshSigBlob
+	if err := ssh.Unmarshal(rest, &blob); err != nil {
+		return ErrSSHSig
+	}
+	if blob.Version != SSHSigVersion {
+		return ErrSSHSig
+	}
+	public, err := ssh.ParsePublicKey(blob.PublicKey)
+	if err != nil {
+		return err
+	}
+	if public.Type() != ssh.KeyAlgoED25519 {
+		return ErrPublicKey
+	}
+	var sig ssh.Signature
+	if err := ssh.Unmarshal(blob.Signature, &sig); err != nil || sig.Format != ssh.KeyAlgoED25519 {
+		return ErrSSHSig
+	}
+	key, err := NewPublicKey(public.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey))
+	if err != nil {
+		return err
+	}
+	*s = SSHSignature{key, blob.Namespace, blob.Hash, sig.Blob}
+	return nil
+}
+
+func (s *SSHSignature) MarshalText() ([]byte, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	enc := base64.StdEncoding.EncodeToString(data)
+	var b bytes.Buffer
+	b.WriteString(armorBegin + "\n")
+	for len(enc) > armorColumns {
+		b.WriteString(enc[:armorColumns] + "\n")
+		enc = enc[armorColumns:]
+	}
+	b.WriteString(enc + "\n")
+	b.WriteString(armorEnd + "\n")
+	return b.Bytes(), nil
+}
+
+func (s *SSHSignature) UnmarshalText(text []byte) error {
+	t := strings.TrimSpace(string(text))
+	t, ok := strings.CutPrefix(t, armorBegin)
+	if !ok {
+		return ErrSSHSig
+	}
+	if t, ok = strings.CutSuffix(t, armorEnd); !ok {
+		return ErrSSHSig
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(t), ""))
+	if err != nil {
+		return ErrSSHSig
+	}
+	return s.UnmarshalBinary(data)
+}
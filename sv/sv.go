@@ -1,6 +1,7 @@
 package sv
 
 import (
+	"crypto"
 	"crypto/ed25519"
 	"fmt"
 )
@@ -17,7 +18,12 @@ func G() (private, public []byte, err error) {
 			err = fmt.Errorf("%v", r)
 		}
 	}()
-	public, private, err = ed25519.GenerateKey(nil)
+	key, err := GenerateKey()
+	if err != nil {
+		return
+	}
+	private = key.Bytes()
+	public = key.PublicKey().Bytes()
 	return
 }
 
@@ -27,8 +33,11 @@ func S(message, private []byte) (signature []byte, err error) {
 			err = fmt.Errorf("%v", r)
 		}
 	}()
-	signature = ed25519.Sign(private, message)
-	return
+	key, err := NewPrivateKey(private)
+	if err != nil {
+		return
+	}
+	return key.Sign(nil, message, crypto.Hash(0))
 }
 
 func V(message, public, signature []byte) (err error) {
@@ -37,6 +46,9 @@ func V(message, public, signature []byte) (err error) {
 			err = fmt.Errorf("%v", r)
 		}
 	}()
-	err = ed25519.VerifyWithOptions(public, message, signature, &ed25519.Options{})
-	return
+	key, err := NewPublicKey(public)
+	if err != nil {
+		return
+	}
+	return key.Verify(message, signature)
 }
@@ -0,0 +1,68 @@
+package sv
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"slices"
+)
+
+var ErrConvert = errors.New("sv: public key has no x25519 form")
+
+var (
+	fieldP   = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	edwardsD = func() *big.Int {
+		d := new(big.Int).ModInverse(big.NewInt(121666), fieldP)
+		d.Mul(d, big.NewInt(-121665))
+		return d.Mod(d, fieldP)
+	}()
+	lowOrder, _ = ecdh.X25519().NewPrivateKey(bytes.Repeat([]byte{1}, 32))
+)
+
+func (k *PrivateKey) X25519() []byte {
+	h := sha512.Sum512(k.key.Seed())
+	s := h[:32]
+	s[0] &= 248
+	s[31] &= 127
+	s[31] |= 64
+	return s
+}
+
+func (k *PublicKey) X25519() ([]byte, error) {
+	b := slices.Clone(k.key)
+	sign := b[31] >> 7
+	b[31] &= 127
+	slices.Reverse(b)
+	y := new(big.Int).SetBytes(b)
+	one := big.NewInt(1)
+	if y.Cmp(fieldP) >= 0 || y.Cmp(one) == 0 {
+		return nil, ErrConvert
+	}
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, fieldP)
+	num := new(big.Int).Sub(y2, one)
+	den := new(big.Int).Mul(edwardsD, y2)
+	den.Add(den, one)
+	x2 := num.Mul(num, den.ModInverse(den.Mod(den, fieldP), fieldP))
+	x2.Mod(x2, fieldP)
+	if x2.Sign() == 0 && sign == 1 || big.Jacobi(x2, fieldP) == -1 {
+		return nil, ErrConvert
+	}
+	den = new(big.Int).Sub(one, y)
+	den.ModInverse(den.Mod(den, fieldP), fieldP)
+	u := new(big.Int).Add(one, y)
+	u.Mul(u, den)
+	u.Mod(u, fieldP)
+	b = u.FillBytes(make([]byte, 32))
+	slices.Reverse(b)
+	public, err := ecdh.X25519().NewPublicKey(b)
+	if err != nil {
+		return nil, ErrConvert
+	}
+	if _, err := lowOrder.ECDH(public); err != nil {
+		return nil, ErrConvert
+	}
+	return b, nil
+}
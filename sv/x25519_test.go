@@ -0,0 +1,61 @@
+package sv_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/jamesliu96/geheim/sv"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestX25519(t *testing.T) {
+	for range 100 {
+		k, err := sv.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		public, err := k.PublicKey().X25519()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := curve25519.X25519(k.X25519(), curve25519.Basepoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(public, want) {
+			t.Fatalf("public %x, want %x", public, want)
+		}
+	}
+}
+
+func TestX25519Invalid(t *testing.T) {
+	for _, s := range []string{
+		// off curve
+		"0200000000000000000000000000000000000000000000000000000000000000",
+		"0700000000000000000000000000000000000000000000000000000000000000",
+		"0800000000000000000000000000000000000000000000000000000000000000",
+		// non-canonical
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		// low order
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"0000000000000000000000000000000000000000000000000000000000000080",
+		"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a",
+		"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac03fa",
+		"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05",
+		"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc85",
+	} {
+		b, _ := hex.DecodeString(s)
+		k, err := sv.NewPublicKey(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := k.X25519(); !errors.Is(err, sv.ErrConvert) {
+			t.Errorf("%s: %v, want %v", s, err, sv.ErrConvert)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package xc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jamesliu96/geheim/sv"
+)
+
+const (
+	TypeCertificate = "CERTIFICATE"
+	TypeRequest     = "CERTIFICATE REQUEST"
+)
+
+const DefaultValidity = 365 * 24 * time.Hour
+
+var (
+	ErrName    = errors.New("xc: invalid subject name")
+	ErrSAN     = errors.New("xc: invalid subject alternative name")
+	ErrPEM     = errors.New("xc: malformed pem")
+	ErrRequest = errors.New("xc: invalid certificate request")
+	ErrIssuer  = errors.New("xc: issuer key does not match certificate")
+	ErrCA      = errors.New("xc: issuer is not a certificate authority")
+)
+
+type SANs struct {
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+}
+
+func ParseName(s string) (name pkix.Name, err error) {
+	s = strings.TrimPrefix(s, "/")
+	if !strings.Contains(s, "=") {
+		name.CommonName = s
+		return
+	}
+	for _, rdn := range strings.FieldsFunc(s, func(c rune) bool { return c == ',' || c == '/' }) {
+		k, v, ok := strings.Cut(rdn, "=")
+		if !ok {
+			err = ErrName
+			return
+		}
+		v = strings.TrimSpace(v)
+		switch strings.ToUpper(strings.TrimSpace(k)) {
+		case "CN":
+			name.CommonName = v
+		case "O":
+			name.Organization = append(name.Organization, v)
+		case "OU":
+			name.OrganizationalUnit = append(name.OrganizationalUnit, v)
+		case "C":
+			name.Country = append(name.Country, v)
+		case "ST":
+			name.Province = append(name.Province, v)
+		case "L":
+			name.Locality = append(name.Locality, v)
+		case "SERIALNUMBER":
+			name.SerialNumber = v
+		default:
+			err = ErrName
+			return
+		}
+	}
+	return
+}
+
+func ParseSANs(values []string) (sans SANs, err error) {
+	for _, v := range values {
+		switch {
+		case net.ParseIP(v) != nil:
+			sans.IPAddresses = append(sans.IPAddresses, net.ParseIP(v))
+		case strings.Contains(v, "://"):
+			var u *url.URL
+			if u, err = url.Parse(v); err != nil {
+				return
+			}
+			sans.URIs = append(sans.URIs, u)
+		case strings.Contains(v, "@"):
+			if _, err = mail.ParseAddress(v); err != nil {
+				return
+			}
+			sans.EmailAddresses = append(sans.EmailAddresses, v)
+		case v != "" && !strings.ContainsAny(v, " /"):
+			sans.DNSNames = append(sans.DNSNames, v)
+		default:
+			err = ErrSAN
+			return
+		}
+	}
+	return
+}
+
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func newTemplate(subject pkix.Name, sans SANs, notBefore, notAfter time.Time, isCA bool) (*x509.Certificate, error) {
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               subject,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		DNSNames:              sans.DNSNames,
+		EmailAddresses:        sans.EmailAddresses,
+		IPAddresses:           sans.IPAddresses,
+		URIs:                  sans.URIs,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+	return template, nil
+}
+
+func SelfSign(key *sv.PrivateKey, subject pkix.Name, sans SANs, notBefore, notAfter time.Time, isCA bool) (*x509.Certificate, error) {
+	template, err := newTemplate(subject, sans, notBefore, notAfter, isCA)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+func CreateRequest(key *sv.PrivateKey, subject pkix.Name, sans SANs) (*x509.CertificateRequest, error) {
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:        subject,
+		DNSNames:       sans.DNSNames,
+		EmailAddresses: sans.EmailAddresses,
+		IPAddresses:    sans.IPAddresses,
+		URIs:           sans.URIs,
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificateRequest(der)
+}
+
+func SignRequest(key *sv.PrivateKey, issuer *x509.Certificate, request *x509.CertificateRequest, notBefore, notAfter time.Time, isCA bool) (*x509.Certificate, error) {
+	if err := request.CheckSignature(); err != nil {
+		return nil, errors.Join(ErrRequest, err)
+	}
+	if !issuer.IsCA {
+		return nil, ErrCA
+	}
+	if public, ok := issuer.PublicKey.(ed25519.PublicKey); !ok || !public.Equal(key.Public()) {
+		return nil, ErrIssuer
+	}
+	template, err := newTemplate(request.Subject, SANs{request.DNSNames, request.EmailAddresses, request.IPAddresses, request.URIs}, notBefore, notAfter, isCA)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, request.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+func EncodeCertificate(c *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: TypeCertificate, Bytes: c.Raw})
+}
+
+func EncodeRequest(r *x509.CertificateRequest) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: TypeRequest, Bytes: r.Raw})
+}
+
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != TypeCertificate {
+		return nil, ErrPEM
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func ParseRequest(data []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != TypeRequest {
+		return nil, ErrPEM
+	}
+	return x509.ParseCertificateRequest(block.Bytes)
+}
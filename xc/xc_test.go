@@ -0,0 +1,102 @@
+package xc_test
+
+import (
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jamesliu96/geheim/sv"
+	"github.com/jamesliu96/geheim/xc"
+)
+
+func TestSignRequest(t *testing.T) {
+	caKey, err := sv.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafKey, err := sv.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	caName, err := xc.ParseName("/CN=Test CA/O=geheim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := xc.SelfSign(caKey, caName, xc.SANs{}, now, now.Add(xc.DefaultValidity), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca, err = xc.ParseCertificate(xc.EncodeCertificate(ca)); err != nil {
+		t.Fatal(err)
+	}
+	leafName, err := xc.ParseName("CN=example.com, O=geheim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sans, err := xc.ParseSANs([]string{"example.com", "127.0.0.1", "user@example.com", "spiffe://example.com/leaf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := xc.CreateRequest(leafKey, leafName, sans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request, err = xc.ParseRequest(xc.EncodeRequest(request)); err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := xc.SignRequest(caKey, ca, request, now, now.Add(xc.DefaultValidity), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf, err = xc.ParseCertificate(xc.EncodeCertificate(leaf)); err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "127.0.0.1", Roots: roots}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "example.org", Roots: roots}); err == nil {
+		t.Fatal("expected hostname error")
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: x509.NewCertPool()}); err == nil {
+		t.Fatal("expected unknown authority error")
+	}
+	if _, err := xc.SignRequest(leafKey, leaf, request, now, now.Add(xc.DefaultValidity), false); !errors.Is(err, xc.ErrCA) {
+		t.Fatalf("%v, want %v", err, xc.ErrCA)
+	}
+	if _, err := xc.SignRequest(leafKey, ca, request, now, now.Add(xc.DefaultValidity), false); !errors.Is(err, xc.ErrIssuer) {
+		t.Fatalf("%v, want %v", err, xc.ErrIssuer)
+	}
+	request.Signature[0] ^= 1
+	if _, err := xc.SignRequest(caKey, ca, request, now, now.Add(xc.DefaultValidity), false); !errors.Is(err, xc.ErrRequest) {
+		t.Fatalf("%v, want %v", err, xc.ErrRequest)
+	}
+}
+
+func TestParse(t *testing.T) {
+	name, err := xc.ParseName("/CN=name/O=a/O=b/C=US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name.CommonName != "name" || len(name.Organization) != 2 || name.Country[0] != "US" {
+		t.Fatalf("name %v", name)
+	}
+	if name, err = xc.ParseName("plain"); err != nil || name.CommonName != "plain" {
+		t.Fatalf("name %v: %v", name, err)
+	}
+	if _, err := xc.ParseName("CN=a,X=b"); !errors.Is(err, xc.ErrName) {
+		t.Fatalf("%v, want %v", err, xc.ErrName)
+	}
+	if _, err := xc.ParseSANs([]string{"not a name"}); !errors.Is(err, xc.ErrSAN) {
+		t.Fatalf("%v, want %v", err, xc.ErrSAN)
+	}
+	if _, err := xc.ParseCertificate([]byte("-----BEGIN CERTIFICATE REQUEST-----\n-----END CERTIFICATE REQUEST-----\n")); !errors.Is(err, xc.ErrPEM) {
+		t.Fatalf("%v, want %v", err, xc.ErrPEM)
+	}
+}
@@ -0,0 +1,112 @@
+package xp
+
+import (
+	"crypto/ecdh"
+	"fmt"
+	"strings"
+)
+
+type Curve int
+
+const (
+	X25519 Curve = 1 + iota
+	P256
+	P384
+	P521
+)
+
+var CurveNames = map[Curve]string{
+	X25519: "X25519",
+	P256:   "P-256",
+	P384:   "P-384",
+	P521:   "P-521",
+}
+
+var PrivateSizes = map[Curve]int{
+	X25519: 32,
+	P256:   32,
+	P384:   48,
+	P521:   66,
+}
+
+var PublicSizes = map[Curve]int{
+	X25519: 32,
+	P256:   1 + 2*32,
+	P384:   1 + 2*48,
+	P521:   1 + 2*66,
+}
+
+var curves = [...]Curve{
+	X25519,
+	P256,
+	P384,
+	P521,
+}
+
+var CurveString = func() string {
+	d := make([]string, len(curves))
+	for i, c := range curves {
+		d[i] = fmt.Sprintf("%d:%s", c, CurveNames[c])
+	}
+	return strings.Join(d, ", ")
+}()
+
+var ErrCurve = fmt.Errorf("xp: invalid curve (%s)", CurveString)
+
+func getCurve(curve Curve) (ecdh.Curve, error) {
+	switch curve {
+	case X25519:
+		return ecdh.X25519(), nil
+	case P256:
+		return ecdh.P256(), nil
+	case P384:
+		return ecdh.P384(), nil
+	case P521:
+		return ecdh.P521(), nil
+	}
+	return nil, ErrCurve
+}
+
+func curveOf(c ecdh.Curve) (Curve, error) {
+	for _, curve := range curves {
+		if cc, _ := getCurve(curve); cc == c {
+			return curve, nil
+		}
+	}
+	return 0, ErrCurve
+}
+
+func PCurve(curve Curve) (private, public []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	key, err := GenerateKey(curve)
+	if err != nil {
+		return
+	}
+	private = key.Bytes()
+	public = key.PublicKey().Bytes()
+	return
+}
+
+func XCurve(curve Curve, scalar, point []byte) (product []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	private, err := NewPrivateKey(curve, scalar)
+	if err != nil {
+		return
+	}
+	if point == nil {
+		return private.PublicKey().Bytes(), nil
+	}
+	public, err := NewPublicKey(curve, point)
+	if err != nil {
+		return
+	}
+	return private.ECDH(public)
+}
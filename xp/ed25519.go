@@ -0,0 +1,15 @@
+package xp
+
+import "github.com/jamesliu96/geheim/sv"
+
+func NewEd25519PrivateKey(key *sv.PrivateKey) (*PrivateKey, error) {
+	return NewPrivateKey(X25519, key.X25519())
+}
+
+func NewEd25519PublicKey(key *sv.PublicKey) (*PublicKey, error) {
+	b, err := key.X25519()
+	if err != nil {
+		return nil, err
+	}
+	return NewPublicKey(X25519, b)
+}
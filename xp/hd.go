@@ -0,0 +1,11 @@
+package xp
+
+import "github.com/jamesliu96/geheim/hd"
+
+func Derive(seed []byte, path string) (*PrivateKey, error) {
+	k, err := hd.Derive(hd.Curve25519, seed, path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(X25519, k.Key)
+}
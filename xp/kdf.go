@@ -0,0 +1,50 @@
+package xp
+
+import (
+	"bytes"
+	"crypto/hkdf"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/jamesliu96/geheim"
+)
+
+const (
+	DefaultHash    = geheim.SHA_256
+	DefaultKeySize = 32
+)
+
+const infoKDF = "KDF"
+
+var ErrSecret = errors.New("xp: degenerate shared secret")
+
+func K(hash geheim.Hash, secret, publicA, publicB []byte, label string, size int) (key []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if len(secret) == 0 || subtle.ConstantTimeCompare(secret, make([]byte, len(secret))) == 1 {
+		err = ErrSecret
+		return
+	}
+	if size < 1 {
+		err = errors.New("xp: invalid key size")
+		return
+	}
+	h, err := geheim.GetHash(hash)
+	if err != nil {
+		return
+	}
+	if bytes.Compare(publicA, publicB) > 0 {
+		publicA, publicB = publicB, publicA
+	}
+	var salt []byte
+	for _, public := range [][]byte{publicA, publicB} {
+		salt = binary.BigEndian.AppendUint32(salt, uint32(len(public)))
+		salt = append(salt, public...)
+	}
+	return hkdf.Key(h, secret, salt, infoKDF+label, size)
+}
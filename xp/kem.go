@@ -0,0 +1,66 @@
+package xp
+
+import (
+	"crypto"
+	"crypto/mlkem"
+	"crypto/subtle"
+)
+
+const (
+	KEMSeedSize       = mlkem.SeedSize
+	KEMPublicSize     = mlkem.EncapsulationKeySize768
+	KEMCiphertextSize = mlkem.CiphertextSize768
+	KEMSharedSize     = mlkem.SharedKeySize
+)
+
+type KEMPrivateKey struct{ key *mlkem.DecapsulationKey768 }
+
+type KEMPublicKey struct{ key *mlkem.EncapsulationKey768 }
+
+func GenerateKEMKey() (*KEMPrivateKey, error) {
+	key, err := mlkem.GenerateKey768()
+	if err != nil {
+		return nil, err
+	}
+	return &KEMPrivateKey{key}, nil
+}
+
+func NewKEMPrivateKey(seed []byte) (*KEMPrivateKey, error) {
+	key, err := mlkem.NewDecapsulationKey768(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &KEMPrivateKey{key}, nil
+}
+
+func NewKEMPublicKey(key []byte) (*KEMPublicKey, error) {
+	k, err := mlkem.NewEncapsulationKey768(key)
+	if err != nil {
+		return nil, err
+	}
+	return &KEMPublicKey{k}, nil
+}
+
+func (k *KEMPrivateKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *KEMPrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(*KEMPrivateKey)
+	return ok && subtle.ConstantTimeCompare(k.Bytes(), xx.Bytes()) == 1
+}
+
+func (k *KEMPrivateKey) Public() crypto.PublicKey { return k.PublicKey() }
+
+func (k *KEMPrivateKey) PublicKey() *KEMPublicKey { return &KEMPublicKey{k.key.EncapsulationKey()} }
+
+func (k *KEMPrivateKey) Decapsulate(ciphertext []byte) (shared []byte, err error) {
+	return k.key.Decapsulate(ciphertext)
+}
+
+func (k *KEMPublicKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *KEMPublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*KEMPublicKey)
+	return ok && subtle.ConstantTimeCompare(k.Bytes(), xx.Bytes()) == 1
+}
+
+func (k *KEMPublicKey) Encapsulate() (shared, ciphertext []byte) { return k.key.Encapsulate() }
@@ -0,0 +1,103 @@
+package xp
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+)
+
+type PrivateKey struct {
+	curve Curve
+	key   *ecdh.PrivateKey
+}
+
+type PublicKey struct {
+	curve Curve
+	key   *ecdh.PublicKey
+}
+
+func GenerateKey(curve Curve) (*PrivateKey, error) {
+	c, err := getCurve(curve)
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{curve, key}, nil
+}
+
+func NewPrivateKey(curve Curve, key []byte) (*PrivateKey, error) {
+	c, err := getCurve(curve)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.NewPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{curve, k}, nil
+}
+
+func NewPublicKey(curve Curve, key []byte) (*PublicKey, error) {
+	c, err := getCurve(curve)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.NewPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicKey{curve, k}, nil
+}
+
+func NewECDHPrivateKey(key *ecdh.PrivateKey) (*PrivateKey, error) {
+	curve, err := curveOf(key.Curve())
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{curve, key}, nil
+}
+
+func NewECDHPublicKey(key *ecdh.PublicKey) (*PublicKey, error) {
+	curve, err := curveOf(key.Curve())
+	if err != nil {
+		return nil, err
+	}
+	return &PublicKey{curve, key}, nil
+}
+
+func (k *PrivateKey) ECDHPrivateKey() *ecdh.PrivateKey { return k.key }
+
+func (k *PublicKey) ECDHPublicKey() *ecdh.PublicKey { return k.key }
+
+func (k *PrivateKey) Curve() Curve { return k.curve }
+
+func (k *PrivateKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(*PrivateKey)
+	return ok && k.curve == xx.curve && k.key.Equal(xx.key)
+}
+
+func (k *PrivateKey) Public() crypto.PublicKey { return k.PublicKey() }
+
+func (k *PrivateKey) PublicKey() *PublicKey { return &PublicKey{k.curve, k.key.PublicKey()} }
+
+func (k *PrivateKey) ECDH(remote *PublicKey) ([]byte, error) {
+	if k.curve != remote.curve {
+		return nil, errors.New("xp: curve mismatch")
+	}
+	return k.key.ECDH(remote.key)
+}
+
+func (k *PublicKey) Curve() Curve { return k.curve }
+
+func (k *PublicKey) Bytes() []byte { return k.key.Bytes() }
+
+func (k *PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*PublicKey)
+	return ok && k.curve == xx.curve && k.key.Equal(xx.key)
+}